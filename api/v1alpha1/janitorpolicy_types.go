@@ -1,7 +1,9 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // JanitorPolicySpec defines the desired state of JanitorPolicy
@@ -10,10 +12,37 @@ type JanitorPolicySpec struct {
 	// +kubebuilder:default=true
 	DryRun bool `json:"dryRun,omitempty"`
 
-	// Schedule defines when cleanup should run (cron format)
-	// +kubebuilder:validation:Pattern=`^(\*|([0-9]|1[0-9]|2[0-9]|3[0-9]|4[0-9]|5[0-9])|\*\/([0-9]|1[0-9]|2[0-9]|3[0-9]|4[0-9]|5[0-9])) (\*|([0-9]|1[0-9]|2[0-3])|\*\/([0-9]|1[0-9]|2[0-3])) (\*|([1-9]|1[0-9]|2[0-9]|3[0-1])|\*\/([1-9]|1[0-9]|2[0-9]|3[0-1])) (\*|([1-9]|1[0-2])|\*\/([1-9]|1[0-2])) (\*|([0-6])|\*\/([0-6]))$`
+	// Schedule defines when cleanup should run. Standard 5-field cron format
+	// (minute hour day-of-month month day-of-week) with ranges, lists and steps,
+	// or a descriptor such as @daily, @hourly or @every 6h. Intervals are given in
+	// whole hours, minutes and seconds and must be at least one minute.
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:XValidation:rule=`self.matches(r"^(@(yearly|annually|monthly|weekly|daily|midnight|hourly)|@every (([1-9][0-9]*h)([0-9]+m)?([0-9]+s)?|([1-9][0-9]*m)([0-9]+s)?)|(\*|([0-5]?[0-9])(-([0-5]?[0-9]))?)(/0*[1-9][0-9]*)?(,(\*|([0-5]?[0-9])(-([0-5]?[0-9]))?)(/0*[1-9][0-9]*)?)* (\*|([01]?[0-9]|2[0-3])(-([01]?[0-9]|2[0-3]))?)(/0*[1-9][0-9]*)?(,(\*|([01]?[0-9]|2[0-3])(-([01]?[0-9]|2[0-3]))?)(/0*[1-9][0-9]*)?)* (\*|\?|(0?[1-9]|[12][0-9]|3[01])(-(0?[1-9]|[12][0-9]|3[01]))?)(/0*[1-9][0-9]*)?(,(\*|\?|(0?[1-9]|[12][0-9]|3[01])(-(0?[1-9]|[12][0-9]|3[01]))?)(/0*[1-9][0-9]*)?)* (\*|(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec))(-(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)))?)(/0*[1-9][0-9]*)?(,(\*|(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec))(-(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)))?)(/0*[1-9][0-9]*)?)* (\*|\?|([0-6]|(?i:sun|mon|tue|wed|thu|fri|sat))(-([0-6]|(?i:sun|mon|tue|wed|thu|fri|sat)))?)(/0*[1-9][0-9]*)?(,(\*|\?|([0-6]|(?i:sun|mon|tue|wed|thu|fri|sat))(-([0-6]|(?i:sun|mon|tue|wed|thu|fri|sat)))?)(/0*[1-9][0-9]*)?)*)$")`,message="schedule must be a 5-field cron expression (minute hour day-of-month month day-of-week) or a descriptor such as @daily or @every 6h, with steps above 0 and @every intervals of at least 1m"
 	Schedule string `json:"schedule,omitempty"`
 
+	// TimeZone - IANA time zone name the schedule is evaluated in, e.g. Europe/Berlin. Defaults to UTC.
+	// +kubebuilder:validation:MaxLength=64
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Jitter - maximum random delay added to each scheduled run, e.g. 10m. Should be shorter than the schedule interval.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	Jitter string `json:"jitter,omitempty"`
+
+	// Suspend - when true, no cleanups are run and the policy is removed from the scheduler.
+	// Runs missed while suspended are not executed on resume.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// ConcurrencyPolicy - how to treat a run that starts while the previous run of this policy is still in progress:
+	// Allow runs both, Forbid skips the new run, Replace cancels the running one
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +kubebuilder:default=Forbid
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+
+	// StartingDeadlineSeconds - how late a run missed while the operator was unavailable may still be started.
+	// Missed runs are not caught up when unset.
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
 	// Cleanup configuration for different resource types
 	Cleanup CleanupConfig `json:"cleanup,omitempty"`
 
@@ -28,6 +57,14 @@ type JanitorPolicySpec struct {
 
 	// NotificationConfig - optional notification settings
 	NotificationConfig *NotificationConfig `json:"notificationConfig,omitempty"`
+
+	// Maintenance - optional windows restricting when resources may be deleted or modified.
+	// Runs outside the windows are downgraded to dry-run.
+	Maintenance *MaintenanceConfig `json:"maintenance,omitempty"`
+
+	// Budget - optional limits on how many resources a single run may delete or modify.
+	// A run whose plan exceeds any limit changes nothing.
+	Budget *DeletionBudget `json:"budget,omitempty"`
 }
 
 // CleanupConfig defines cleanup configuration for different resource types
@@ -64,6 +101,10 @@ type CleanupConfig struct {
 
 	// RBACCheck configuration
 	RBACCheck *RBACCheckConfig `json:"rbacCheck,omitempty"`
+
+	// Extensions - configuration of out-of-tree cleaners, keyed by cleaner name
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Extensions map[string]runtime.RawExtension `json:"extensions,omitempty"`
 }
 
 // PVCCleanupConfig defines PVC cleanup parameters
@@ -97,6 +138,10 @@ type JobsCleanupConfig struct {
 
 	// KeepFailedJobs - number of failed jobs to keep
 	KeepFailedJobs *int32 `json:"keepFailedJobs,omitempty"`
+
+	// GroupByLabel - label used to group Jobs not owned by a CronJob when applying the keep counts.
+	// Jobs without an owning CronJob or this label are grouped per namespace.
+	GroupByLabel string `json:"groupByLabel,omitempty"`
 }
 
 // ConfigMapsCleanupConfig defines ConfigMaps cleanup parameters
@@ -138,6 +183,11 @@ type ServicesCleanupConfig struct {
 	// CheckEndpoints - whether to check for backing endpoints
 	// +kubebuilder:default=true
 	CheckEndpoints bool `json:"checkEndpoints,omitempty"`
+
+	// EmptyFor - how long a Service must have had no ready endpoints before cleanup
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:default="1h"
+	EmptyFor string `json:"emptyFor,omitempty"`
 }
 
 // TLSSecretsCleanupConfig defines TLS Secrets cleanup parameters
@@ -145,11 +195,12 @@ type TLSSecretsCleanupConfig struct {
 	// Enabled - whether TLS Secrets cleanup is enabled
 	Enabled bool `json:"enabled,omitempty"`
 
-	// ExpiredOnly - only clean up expired certificates
+	// ExpiredOnly - only clean up expired certificates. Kept for compatibility: certificates that
+	// are still valid are never deleted, whatever its value.
 	// +kubebuilder:default=true
 	ExpiredOnly bool `json:"expiredOnly,omitempty"`
 
-	// ExpiringWithin - clean up certificates expiring within this duration
+	// ExpiringWithin - report certificates expiring within this duration through events and findings
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
 	ExpiringWithin string `json:"expiringWithin,omitempty"`
 }
@@ -162,6 +213,12 @@ type TerminatingPodsCleanupConfig struct {
 	// StuckFor - how long a pod can be stuck in terminating state
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
 	StuckFor string `json:"stuckFor,omitempty"`
+
+	// Strategy - how to unblock stuck pods (auto, forceDelete, removeFinalizers).
+	// auto force-deletes pods on NotReady or missing nodes and strips finalizers from pods blocked by them.
+	// +kubebuilder:validation:Enum=auto;forceDelete;removeFinalizers
+	// +kubebuilder:default=auto
+	Strategy string `json:"strategy,omitempty"`
 }
 
 // StaleHelmReleasesCleanupConfig defines Helm releases cleanup parameters
@@ -176,6 +233,10 @@ type StaleHelmReleasesCleanupConfig struct {
 	// OlderThan - delete releases older than this duration
 	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
 	OlderThan string `json:"olderThan,omitempty"`
+
+	// MaxHistory - number of most recent revisions to keep per release; older superseded revisions are pruned
+	// +kubebuilder:validation:Minimum=1
+	MaxHistory *int32 `json:"maxHistory,omitempty"`
 }
 
 // ResourceGapsConfig defines resource gaps detection parameters
@@ -190,6 +251,18 @@ type ResourceGapsConfig struct {
 	// ReportOnly - only report gaps, don't attempt to fix
 	// +kubebuilder:default=true
 	ReportOnly bool `json:"reportOnly,omitempty"`
+
+	// DefaultProfile - resources patched into workload templates when ReportOnly is false
+	DefaultProfile *ResourceProfile `json:"defaultProfile,omitempty"`
+}
+
+// ResourceProfile defines default container resources
+type ResourceProfile struct {
+	// Requests - default resource requests
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+
+	// Limits - default resource limits
+	Limits corev1.ResourceList `json:"limits,omitempty"`
 }
 
 // CrashLoopPodsConfig defines crash loop pods handling parameters
@@ -216,6 +289,12 @@ type RBACCheckConfig struct {
 	// +kubebuilder:validation:Enum=manual;suggest;auto
 	// +kubebuilder:default=manual
 	FixMode string `json:"fixMode,omitempty"`
+
+	// OlderThan - minimum age of a binding before it is deleted in auto fix mode. Younger bindings
+	// are reported, as their ServiceAccounts may not have been created yet.
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:default="1h"
+	OlderThan string `json:"olderThan,omitempty"`
 }
 
 // BackupConfig defines backup configuration
@@ -234,6 +313,73 @@ type BackupConfig struct {
 	RetentionDays int32 `json:"retentionDays,omitempty"`
 }
 
+// DeletionBudget caps the number of resources a single run may delete or modify
+type DeletionBudget struct {
+	// Limits applied to the plan of all cleaners together
+	BudgetLimits `json:",inline"`
+
+	// Cleaners - limits applied to the plan of individual cleaners, keyed by cleaner name, e.g. pvc or jobs
+	Cleaners map[string]BudgetLimits `json:"cleaners,omitempty"`
+}
+
+// BudgetLimits defines the limits of a deletion budget. Unset limits are not enforced.
+type BudgetLimits struct {
+	// MaxDeletions - maximum number of resources deleted or modified in one run
+	// +kubebuilder:validation:Minimum=0
+	MaxDeletions *int32 `json:"maxDeletions,omitempty"`
+
+	// MaxPercent - maximum percentage of the scanned resources deleted or modified in one run
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxPercent *int32 `json:"maxPercent,omitempty"`
+
+	// MaxPerNamespace - maximum number of resources deleted or modified in a single namespace in one run
+	// +kubebuilder:validation:Minimum=0
+	MaxPerNamespace *int32 `json:"maxPerNamespace,omitempty"`
+}
+
+// MaintenanceConfig defines when destructive actions are allowed
+type MaintenanceConfig struct {
+	// TimeZone - IANA time zone the windows and blackouts are evaluated in. Defaults to the policy time zone.
+	// +kubebuilder:validation:MaxLength=64
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Windows - periods in which destructive actions are allowed. When empty, actions are allowed outside blackouts.
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+
+	// Blackouts - date ranges in which destructive actions are never allowed, e.g. release freezes
+	Blackouts []BlackoutPeriod `json:"blackouts,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring period in which destructive actions are allowed
+type MaintenanceWindow struct {
+	// Days - days of the week the window starts on. Every day when empty.
+	// +kubebuilder:validation:items:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+	Days []string `json:"days,omitempty"`
+
+	// Start - time of day the window opens (HH:MM)
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End - time of day the window closes (HH:MM). A window ending at or before its start spans midnight.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// BlackoutPeriod defines a date range in which destructive actions are not allowed
+type BlackoutPeriod struct {
+	// Name - description of the blackout, e.g. "Year-end freeze"
+	Name string `json:"name,omitempty"`
+
+	// Start - first day of the blackout (YYYY-MM-DD)
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	Start string `json:"start"`
+
+	// End - last day of the blackout, inclusive (YYYY-MM-DD)
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	End string `json:"end"`
+}
+
 // NotificationConfig defines notification configuration
 type NotificationConfig struct {
 	// Slack configuration
@@ -300,6 +446,19 @@ type JanitorPolicyStatus struct {
 	// Message - human readable message about the current status
 	Message string `json:"message,omitempty"`
 
+	// LastRunNowToken - value of the janitor.io/run-now annotation that last triggered an on-demand run
+	LastRunNowToken string `json:"lastRunNowToken,omitempty"`
+
+	// SkippedRuns - number of runs skipped because a previous run was still in progress
+	SkippedRuns int32 `json:"skippedRuns,omitempty"`
+
+	// LastSkippedRun - timestamp of the last skipped run
+	LastSkippedRun *metav1.Time `json:"lastSkippedRun,omitempty"`
+
+	// MaintenanceWindow - whether destructive actions are currently allowed by the maintenance configuration
+	// +kubebuilder:validation:Enum=Open;Closed;Blackout
+	MaintenanceWindow string `json:"maintenanceWindow,omitempty"`
+
 	// Stats - cleanup statistics from the last run
 	Stats *CleanupStats `json:"stats,omitempty"`
 
@@ -323,6 +482,39 @@ type CleanupStats struct {
 
 	// ByResourceType - breakdown by resource type
 	ByResourceType map[string]ResourceTypeStats `json:"byResourceType,omitempty"`
+
+	// Findings - issues reported during the run that were not acted upon
+	Findings []Finding `json:"findings,omitempty"`
+
+	// DowngradedToDryRun - the run was performed as a dry-run because it was outside the maintenance windows
+	DowngradedToDryRun bool `json:"downgradedToDryRun,omitempty"`
+
+	// BudgetExceeded - the deletion budget limit exceeded by the plan of the run, in which case nothing was changed
+	BudgetExceeded string `json:"budgetExceeded,omitempty"`
+}
+
+// Finding describes an issue reported by a cleaner without deleting the resource
+type Finding struct {
+	// Cleaner - name of the cleaner that reported the finding
+	Cleaner string `json:"cleaner,omitempty"`
+
+	// Kind - kind of the affected resource
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace - namespace of the affected resource
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name - name of the affected resource
+	Name string `json:"name,omitempty"`
+
+	// Reason - machine readable reason for the finding
+	Reason string `json:"reason,omitempty"`
+
+	// Message - human readable description of the finding
+	Message string `json:"message,omitempty"`
+
+	// Suggestion - suggested fix, such as a YAML patch
+	Suggestion string `json:"suggestion,omitempty"`
 }
 
 // ResourceTypeStats defines statistics for a specific resource type
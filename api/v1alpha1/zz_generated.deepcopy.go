@@ -22,6 +22,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -41,6 +42,51 @@ func (in *BackupConfig) DeepCopy() *BackupConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlackoutPeriod) DeepCopyInto(out *BlackoutPeriod) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlackoutPeriod.
+func (in *BlackoutPeriod) DeepCopy() *BlackoutPeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(BlackoutPeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BudgetLimits) DeepCopyInto(out *BudgetLimits) {
+	*out = *in
+	if in.MaxDeletions != nil {
+		in, out := &in.MaxDeletions, &out.MaxDeletions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPercent != nil {
+		in, out := &in.MaxPercent, &out.MaxPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPerNamespace != nil {
+		in, out := &in.MaxPerNamespace, &out.MaxPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetLimits.
+func (in *BudgetLimits) DeepCopy() *BudgetLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(BudgetLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 	*out = *in
@@ -82,7 +128,7 @@ func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 	if in.StaleHelmReleases != nil {
 		in, out := &in.StaleHelmReleases, &out.StaleHelmReleases
 		*out = new(StaleHelmReleasesCleanupConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.ResourceGaps != nil {
 		in, out := &in.ResourceGaps, &out.ResourceGaps
@@ -99,6 +145,13 @@ func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 		*out = new(RBACCheckConfig)
 		**out = **in
 	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupConfig.
@@ -121,6 +174,11 @@ func (in *CleanupStats) DeepCopyInto(out *CleanupStats) {
 			(*out)[key] = val
 		}
 	}
+	if in.Findings != nil {
+		in, out := &in.Findings, &out.Findings
+		*out = make([]Finding, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupStats.
@@ -163,6 +221,29 @@ func (in *CrashLoopPodsConfig) DeepCopy() *CrashLoopPodsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionBudget) DeepCopyInto(out *DeletionBudget) {
+	*out = *in
+	in.BudgetLimits.DeepCopyInto(&out.BudgetLimits)
+	if in.Cleaners != nil {
+		in, out := &in.Cleaners, &out.Cleaners
+		*out = make(map[string]BudgetLimits, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionBudget.
+func (in *DeletionBudget) DeepCopy() *DeletionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EmailConfig) DeepCopyInto(out *EmailConfig) {
 	*out = *in
@@ -183,6 +264,21 @@ func (in *EmailConfig) DeepCopy() *EmailConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Finding) DeepCopyInto(out *Finding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Finding.
+func (in *Finding) DeepCopy() *Finding {
+	if in == nil {
+		return nil
+	}
+	out := new(Finding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JanitorPolicy) DeepCopyInto(out *JanitorPolicy) {
 	*out = *in
@@ -245,6 +341,11 @@ func (in *JanitorPolicyList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JanitorPolicySpec) DeepCopyInto(out *JanitorPolicySpec) {
 	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	in.Cleanup.DeepCopyInto(&out.Cleanup)
 	if in.ProtectedLabels != nil {
 		in, out := &in.ProtectedLabels, &out.ProtectedLabels
@@ -266,6 +367,16 @@ func (in *JanitorPolicySpec) DeepCopyInto(out *JanitorPolicySpec) {
 		*out = new(NotificationConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(DeletionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JanitorPolicySpec.
@@ -289,6 +400,10 @@ func (in *JanitorPolicyStatus) DeepCopyInto(out *JanitorPolicyStatus) {
 		in, out := &in.NextRun, &out.NextRun
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSkippedRun != nil {
+		in, out := &in.LastSkippedRun, &out.LastSkippedRun
+		*out = (*in).DeepCopy()
+	}
 	if in.Stats != nil {
 		in, out := &in.Stats, &out.Stats
 		*out = new(CleanupStats)
@@ -343,6 +458,53 @@ func (in *JobsCleanupConfig) DeepCopy() *JobsCleanupConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceConfig) DeepCopyInto(out *MaintenanceConfig) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Blackouts != nil {
+		in, out := &in.Blackouts, &out.Blackouts
+		*out = make([]BlackoutPeriod, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceConfig.
+func (in *MaintenanceConfig) DeepCopy() *MaintenanceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NotificationConfig) DeepCopyInto(out *NotificationConfig) {
 	*out = *in
@@ -416,6 +578,11 @@ func (in *ResourceGapsConfig) DeepCopyInto(out *ResourceGapsConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DefaultProfile != nil {
+		in, out := &in.DefaultProfile, &out.DefaultProfile
+		*out = new(ResourceProfile)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceGapsConfig.
@@ -428,6 +595,35 @@ func (in *ResourceGapsConfig) DeepCopy() *ResourceGapsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceProfile) DeepCopyInto(out *ResourceProfile) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceProfile.
+func (in *ResourceProfile) DeepCopy() *ResourceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceTypeStats) DeepCopyInto(out *ResourceTypeStats) {
 	*out = *in
@@ -496,6 +692,11 @@ func (in *SlackConfig) DeepCopy() *SlackConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StaleHelmReleasesCleanupConfig) DeepCopyInto(out *StaleHelmReleasesCleanupConfig) {
 	*out = *in
+	if in.MaxHistory != nil {
+		in, out := &in.MaxHistory, &out.MaxHistory
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaleHelmReleasesCleanupConfig.
@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
+	_ "time/tzdata"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -31,11 +33,19 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var logLevel string
+	var scheduleSpread time.Duration
+	var cleanupParallelism int
+	var cleanupQPS float64
+	var cleanupBurst int
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
 	flag.StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
+	flag.DurationVar(&scheduleSpread, "schedule-spread", 0, "Spread scheduled runs of all policies over this duration, using an offset derived from each policy UID. Disabled when 0.")
+	flag.IntVar(&cleanupParallelism, "cleanup-parallelism", 4, "Number of cleaners and resource changes processed concurrently across all cleanup runs.")
+	flag.Float64Var(&cleanupQPS, "cleanup-qps", 20, "Maximum deletes and patches per second across all cleanup runs. Unlimited when 0.")
+	flag.IntVar(&cleanupBurst, "cleanup-burst", 30, "Maximum burst of deletes and patches above --cleanup-qps.")
 
 	opts := zap.Options{
 		Development: false,
@@ -56,10 +66,14 @@ func main() {
 	}
 
 	if err = (&controllers.JanitorPolicyReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorderFor("kubejanitor-operator"),
-		Log:      ctrl.Log.WithName("controllers").WithName("JanitorPolicy"),
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           mgr.GetEventRecorderFor("kubejanitor-operator"),
+		Log:                ctrl.Log.WithName("controllers").WithName("JanitorPolicy"),
+		ScheduleSpread:     scheduleSpread,
+		CleanupParallelism: cleanupParallelism,
+		CleanupQPS:         float32(cleanupQPS),
+		CleanupBurst:       cleanupBurst,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JanitorPolicy")
 		os.Exit(1)
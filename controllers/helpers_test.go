@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+	cron "github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+	"github.com/automationpi/kubejanitor/pkg/cleanup"
+)
+
+// newTestReconciler returns a reconciler backed by a fake client holding the objects, set up as
+// SetupWithManager does but without a manager. Its cron scheduler is not started.
+func newTestReconciler(objects ...client.Object) *JanitorPolicyReconciler {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	if err := opsv1alpha1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+
+	return &JanitorPolicyReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithStatusSubresource(&opsv1alpha1.JanitorPolicy{}).
+			Build(),
+		Scheme:        scheme,
+		Recorder:      record.NewFakeRecorder(100),
+		Log:           logr.Discard(),
+		cronScheduler: cron.New(cron.WithParser(scheduleParser)),
+		cleanupEngine: cleanup.NewEngine(cleanup.NewWorkerPool(1, 0, 0)),
+		entries:       make(map[types.UID]scheduledEntry),
+		missedChecked: make(map[types.UID]bool),
+		runs:          make(map[types.UID][]*activeRun),
+		resync:        make(chan event.GenericEvent, 10),
+	}
+}
@@ -3,35 +3,53 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	cron "github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
 	"github.com/automationpi/kubejanitor/pkg/cleanup"
 	"github.com/automationpi/kubejanitor/pkg/metrics"
+	"github.com/automationpi/kubejanitor/pkg/notification"
 )
 
 const (
 	// FinalizerName is the finalizer name for JanitorPolicy
 	FinalizerName = "janitorpolicy.janitor.io/finalizer"
 
+	// RunNowAnnotation triggers an immediate cleanup run whenever its value changes
+	RunNowAnnotation = "janitor.io/run-now"
+
 	// ConditionTypeReady represents the ready condition
 	ConditionTypeReady = "Ready"
 
 	// ConditionTypeScheduled represents the scheduled condition
 	ConditionTypeScheduled = "Scheduled"
 
+	// ConditionTypeMissedSchedule represents a scheduled run that was missed
+	ConditionTypeMissedSchedule = "MissedSchedule"
+
+	// ConditionTypeBudgetExceeded represents a run whose plan exceeded the deletion budget
+	ConditionTypeBudgetExceeded = "BudgetExceeded"
+
 	// ReasonSucceeded represents successful operation
 	ReasonSucceeded = "Succeeded"
 
@@ -41,6 +59,42 @@ const (
 	// ReasonScheduled represents scheduled operation
 	ReasonScheduled = "Scheduled"
 
+	// ReasonRunNow represents an on-demand run
+	ReasonRunNow = "RunNow"
+
+	// ReasonSuspended represents a suspended policy
+	ReasonSuspended = "Suspended"
+
+	// PhaseActive is the phase of a policy that is being scheduled
+	PhaseActive = "Active"
+
+	// PhasePaused is the phase of a suspended policy
+	PhasePaused = "Paused"
+
+	// ReasonRunSkipped represents a run that was skipped
+	ReasonRunSkipped = "RunSkipped"
+
+	// ReasonDeadlineExceeded represents a missed run that is too late to start
+	ReasonDeadlineExceeded = "DeadlineExceeded"
+
+	// ReasonCaughtUp represents a missed run that was started late
+	ReasonCaughtUp = "CaughtUp"
+
+	// ReasonBudgetExceeded represents a plan that was not applied because it exceeded the deletion budget
+	ReasonBudgetExceeded = "BudgetExceeded"
+
+	// ReasonWithinBudget represents a plan within the deletion budget
+	ReasonWithinBudget = "WithinBudget"
+
+	// ConcurrencyPolicyAllow allows runs of the same policy to overlap
+	ConcurrencyPolicyAllow = "Allow"
+
+	// ConcurrencyPolicyForbid skips a run while the previous run is in progress
+	ConcurrencyPolicyForbid = "Forbid"
+
+	// ConcurrencyPolicyReplace cancels the run in progress and starts the new one
+	ConcurrencyPolicyReplace = "Replace"
+
 	// EventTypeNormal represents normal event
 	EventTypeNormal = "Normal"
 
@@ -48,6 +102,30 @@ const (
 	EventTypeWarning = "Warning"
 )
 
+// scheduleParser accepts the schedule grammar validated by the JanitorPolicy CRD:
+// standard 5-field cron expressions and descriptors such as @daily or @every 6h
+var scheduleParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// everyPattern is the @every grammar of the CRD validation: intervals of at least one minute in
+// whole hours, minutes and seconds
+var everyPattern = regexp.MustCompile(`^@every (([1-9][0-9]*h)([0-9]+m)?([0-9]+s)?|([1-9][0-9]*m)([0-9]+s)?)$`)
+
+// scheduledEntry tracks the cron entry registered for a JanitorPolicy
+type scheduledEntry struct {
+	EntryID  cron.EntryID
+	Name     types.NamespacedName
+	Schedule string
+	TimeZone string
+	Jitter   string
+}
+
+// activeRun tracks a cleanup run in progress
+type activeRun struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
 // JanitorPolicyReconciler reconciles a JanitorPolicy object
 type JanitorPolicyReconciler struct {
 	client.Client
@@ -55,18 +133,51 @@ type JanitorPolicyReconciler struct {
 	Recorder record.EventRecorder
 	Log      logr.Logger
 
+	// ScheduleSpread - when set, every policy is delayed by an offset within this duration derived from its UID
+	ScheduleSpread time.Duration
+
+	// CleanupParallelism - number of cleaners and changes processed concurrently across all cleanup runs
+	CleanupParallelism int
+
+	// CleanupQPS - maximum rate of deletes and patches per second across all cleanup runs, unlimited when 0
+	CleanupQPS float32
+
+	// CleanupBurst - maximum burst of deletes and patches above CleanupQPS
+	CleanupBurst int
+
 	// Internal state
 	cronScheduler *cron.Cron
 	cleanupEngine *cleanup.Engine
 	metricsServer *metrics.Server
+	notifier      *notification.Notifier
+
+	// Scheduled cron entries by policy UID, valid while this manager is the leader
+	entriesMu    sync.Mutex
+	schedulerCtx context.Context
+	entries      map[types.UID]scheduledEntry
+	// Policies already checked for runs missed before this scheduler started
+	missedChecked map[types.UID]bool
+	// Policies enqueued by the scheduler when it starts, to rebuild their entries
+	resync chan event.GenericEvent
+
+	// Cleanup runs in progress by policy UID. Overlapping runs are all tracked, so that a
+	// later run with another concurrency policy sees every one of them.
+	runsMu sync.Mutex
+	runs   map[types.UID][]*activeRun
 }
 
 //+kubebuilder:rbac:groups=janitor.io,resources=janitorpolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=janitor.io,resources=janitorpolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=janitor.io,resources=janitorpolicies/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims;configmaps;secrets;services;events,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=serviceaccounts;nodes;limitranges,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=deployments;replicasets;daemonsets;statefulsets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=patch;update
 //+kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=patch;update
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways;httproutes;grpcroutes;tlsroutes;tcproutes;udproutes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings;clusterroles;clusterrolebindings,verbs=get;list;watch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -79,6 +190,7 @@ func (r *JanitorPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if err := r.Get(ctx, req.NamespacedName, &janitorPolicy); err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("JanitorPolicy resource not found. Ignoring since object must be deleted")
+			r.removeFromSchedulerByName(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		log.Error(err, "Failed to get JanitorPolicy")
@@ -102,45 +214,117 @@ func (r *JanitorPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Initialize status if needed
 	if janitorPolicy.Status.Phase == "" {
-		janitorPolicy.Status.Phase = "Active"
+		janitorPolicy.Status.Phase = PhaseActive
 		if err := r.Status().Update(ctx, &janitorPolicy); err != nil {
 			log.Error(err, "Failed to update status")
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Suspended policies are removed from the scheduler until resumed
+	if janitorPolicy.Spec.Suspend {
+		return r.handleSuspend(ctx, &janitorPolicy)
+	}
+	if janitorPolicy.Status.Phase == PhasePaused {
+		log.Info("Resuming JanitorPolicy")
+		r.Recorder.Event(&janitorPolicy, EventTypeNormal, ReasonScheduled, "JanitorPolicy resumed")
+		janitorPolicy.Status.Phase = PhaseActive
+	}
+
 	// Schedule cleanup if schedule is configured
 	if janitorPolicy.Spec.Schedule != "" {
 		if err := r.scheduleCleanup(ctx, &janitorPolicy); err != nil {
 			log.Error(err, "Failed to schedule cleanup")
 			r.updateCondition(&janitorPolicy, ConditionTypeScheduled, metav1.ConditionFalse, ReasonFailed, err.Error())
 			r.Recorder.Event(&janitorPolicy, EventTypeWarning, ReasonFailed, fmt.Sprintf("Failed to schedule cleanup: %v", err))
+			r.removeFromScheduler(&janitorPolicy)
+			janitorPolicy.Status.NextRun = nil
+			if updateErr := r.Status().Update(ctx, &janitorPolicy); updateErr != nil {
+				log.Error(updateErr, "Failed to update status")
+			}
 			return ctrl.Result{}, err
 		}
 		r.updateCondition(&janitorPolicy, ConditionTypeScheduled, metav1.ConditionTrue, ReasonScheduled, "Cleanup scheduled successfully")
+	} else {
+		r.removeFromScheduler(&janitorPolicy)
+		janitorPolicy.Status.NextRun = nil
+	}
+
+	// Surface whether destructive actions are currently allowed
+	janitorPolicy.Status.MaintenanceWindow = ""
+	if janitorPolicy.Spec.Maintenance != nil {
+		janitorPolicy.Status.MaintenanceWindow, _ = cleanup.MaintenanceState(&janitorPolicy, time.Now())
 	}
 
 	// Update ready condition
 	r.updateCondition(&janitorPolicy, ConditionTypeReady, metav1.ConditionTrue, ReasonSucceeded, "JanitorPolicy is ready")
 
+	// Record a new run-now token before running so the same token never triggers twice
+	runNowToken := janitorPolicy.Annotations[RunNowAnnotation]
+	runNow := runNowToken != "" && runNowToken != janitorPolicy.Status.LastRunNowToken
+	if runNow {
+		janitorPolicy.Status.LastRunNowToken = runNowToken
+	}
+
 	// Update status
 	if err := r.Status().Update(ctx, &janitorPolicy); err != nil {
 		log.Error(err, "Failed to update status")
 		return ctrl.Result{}, err
 	}
 
+	if runNow {
+		log.Info("Triggering on-demand cleanup", "token", runNowToken)
+		r.Recorder.Event(&janitorPolicy, EventTypeNormal, ReasonRunNow, fmt.Sprintf("On-demand cleanup triggered by token %q", runNowToken))
+		go r.runScheduledCleanup(req.NamespacedName, janitorPolicy.UID)
+	}
+
 	// Requeue to check for schedule updates
 	return ctrl.Result{RequeueAfter: time.Minute * 5}, nil
 }
 
+// handleSuspend removes a suspended policy from the scheduler and marks it as paused
+func (r *JanitorPolicyReconciler) handleSuspend(ctx context.Context, janitorPolicy *opsv1alpha1.JanitorPolicy) (ctrl.Result, error) {
+	log := r.Log.WithValues("janitorpolicy", janitorPolicy.Name)
+
+	r.removeFromScheduler(janitorPolicy)
+
+	// Runs missed while suspended are not caught up on resume
+	r.entriesMu.Lock()
+	r.missedChecked[janitorPolicy.UID] = true
+	r.entriesMu.Unlock()
+
+	if janitorPolicy.Status.Phase != PhasePaused {
+		log.Info("Suspending JanitorPolicy")
+		r.Recorder.Event(janitorPolicy, EventTypeNormal, ReasonSuspended, "JanitorPolicy suspended, no cleanups will run")
+	}
+
+	janitorPolicy.Status.Phase = PhasePaused
+	janitorPolicy.Status.NextRun = nil
+	r.updateCondition(janitorPolicy, ConditionTypeScheduled, metav1.ConditionFalse, ReasonSuspended, "JanitorPolicy is suspended")
+
+	// Run-now tokens received while suspended are consumed without running
+	if token := janitorPolicy.Annotations[RunNowAnnotation]; token != "" && token != janitorPolicy.Status.LastRunNowToken {
+		janitorPolicy.Status.LastRunNowToken = token
+		r.Recorder.Event(janitorPolicy, EventTypeWarning, ReasonSuspended, fmt.Sprintf("Ignoring on-demand cleanup token %q while suspended", token))
+	}
+
+	if err := r.Status().Update(ctx, janitorPolicy); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
 // handleDeletion handles the deletion of JanitorPolicy
 func (r *JanitorPolicyReconciler) handleDeletion(ctx context.Context, janitorPolicy *opsv1alpha1.JanitorPolicy) (ctrl.Result, error) {
 	log := r.Log.WithValues("janitorpolicy", janitorPolicy.Name)
 
 	// Remove from scheduler if scheduled
-	if janitorPolicy.Spec.Schedule != "" {
-		r.removeFromScheduler(janitorPolicy)
-	}
+	r.removeFromScheduler(janitorPolicy)
+	r.entriesMu.Lock()
+	delete(r.missedChecked, janitorPolicy.UID)
+	r.entriesMu.Unlock()
 
 	// Remove finalizer
 	controllerutil.RemoveFinalizer(janitorPolicy, FinalizerName)
@@ -153,41 +337,296 @@ func (r *JanitorPolicyReconciler) handleDeletion(ctx context.Context, janitorPol
 	return ctrl.Result{}, nil
 }
 
-// scheduleCleanup schedules the cleanup job based on the cron schedule
+// scheduleCleanup schedules the cleanup job based on the cron schedule.
+// The existing cron entry is kept unless the schedule has changed.
 func (r *JanitorPolicyReconciler) scheduleCleanup(ctx context.Context, janitorPolicy *opsv1alpha1.JanitorPolicy) error {
 	log := r.Log.WithValues("janitorpolicy", janitorPolicy.Name)
 
-	// Remove existing schedule if any
-	r.removeFromScheduler(janitorPolicy)
-
 	// Parse and validate cron schedule
-	schedule, err := cron.ParseStandard(janitorPolicy.Spec.Schedule)
+	schedule, err := r.policySchedule(janitorPolicy)
 	if err != nil {
-		return fmt.Errorf("invalid cron schedule: %w", err)
+		return err
 	}
 
-	// Add to scheduler
-	entryID, err := r.cronScheduler.AddFunc(janitorPolicy.Spec.Schedule, func() {
-		r.executeCleanup(ctx, janitorPolicy)
-	})
-	if err != nil {
-		return fmt.Errorf("failed to add to scheduler: %w", err)
+	r.entriesMu.Lock()
+	defer r.entriesMu.Unlock()
+
+	if existing, found := r.entries[janitorPolicy.UID]; found {
+		if existing.Schedule == janitorPolicy.Spec.Schedule && existing.TimeZone == janitorPolicy.Spec.TimeZone &&
+			existing.Jitter == janitorPolicy.Spec.Jitter {
+			janitorPolicy.Status.NextRun = nextRunTime(schedule, time.Now())
+			return nil
+		}
+
+		// Schedule changed, replace the entry
+		r.cronScheduler.Remove(existing.EntryID)
+		delete(r.entries, janitorPolicy.UID)
+		log.Info("Removed outdated schedule", "schedule", existing.Schedule, "timeZone", existing.TimeZone, "entryID", existing.EntryID)
+	}
+
+	// Add to scheduler. The job only captures the policy identity and refetches it when it fires.
+	key := types.NamespacedName{Name: janitorPolicy.Name, Namespace: janitorPolicy.Namespace}
+	uid := janitorPolicy.UID
+	entryID := r.cronScheduler.Schedule(schedule, cron.FuncJob(func() {
+		r.runScheduledCleanup(key, uid)
+	}))
+
+	r.entries[janitorPolicy.UID] = scheduledEntry{
+		EntryID:  entryID,
+		Name:     key,
+		Schedule: janitorPolicy.Spec.Schedule,
+		TimeZone: janitorPolicy.Spec.TimeZone,
+		Jitter:   janitorPolicy.Spec.Jitter,
+	}
+
+	// Runs missed while the operator was unavailable are only checked once
+	if !r.missedChecked[janitorPolicy.UID] {
+		r.missedChecked[janitorPolicy.UID] = true
+		r.checkMissedRun(janitorPolicy, schedule, time.Now())
 	}
 
 	// Update next run time
-	nextRun := schedule.Next(time.Now())
-	janitorPolicy.Status.NextRun = &metav1.Time{Time: nextRun}
+	janitorPolicy.Status.NextRun = nextRunTime(schedule, time.Now())
 
-	log.Info("Cleanup scheduled", "schedule", janitorPolicy.Spec.Schedule, "nextRun", nextRun, "entryID", entryID)
+	log.Info("Cleanup scheduled", "schedule", janitorPolicy.Spec.Schedule, "timeZone", janitorPolicy.Spec.TimeZone, "nextRun", janitorPolicy.Status.NextRun, "entryID", entryID)
 	return nil
 }
 
-// removeFromScheduler removes the cleanup job from the scheduler
-func (r *JanitorPolicyReconciler) removeFromScheduler(janitorPolicy *opsv1alpha1.JanitorPolicy) {
-	// This is a simplified implementation
-	// In a real implementation, you would track entry IDs and remove them
+// nextRunTime returns the next activation of the schedule after now, or nil if it never fires again
+func nextRunTime(schedule cron.Schedule, now time.Time) *metav1.Time {
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	return &metav1.Time{Time: next}
+}
+
+// checkMissedRun starts a run missed since the last run if it is within the starting deadline,
+// and otherwise records the MissedSchedule condition
+func (r *JanitorPolicyReconciler) checkMissedRun(janitorPolicy *opsv1alpha1.JanitorPolicy, schedule cron.Schedule, now time.Time) {
 	log := r.Log.WithValues("janitorpolicy", janitorPolicy.Name)
-	log.Info("Removing from scheduler")
+
+	lastRun := janitorPolicy.CreationTimestamp.Time
+	if janitorPolicy.Status.LastRun != nil {
+		lastRun = janitorPolicy.Status.LastRun.Time
+	}
+
+	// A zero time means the schedule has no activation after the last run
+	missed := schedule.Next(lastRun)
+	if missed.IsZero() || missed.After(now) {
+		return
+	}
+
+	// Look for a missed run no older than the deadline
+	if deadline := janitorPolicy.Spec.StartingDeadlineSeconds; deadline != nil {
+		earliest := now.Add(-time.Duration(*deadline) * time.Second)
+		if earliest.After(lastRun) {
+			missed = schedule.Next(earliest)
+		}
+		if !missed.IsZero() && !missed.After(now) {
+			message := fmt.Sprintf("Starting run missed at %s", missed.UTC().Format(time.RFC3339))
+			log.Info("Catching up missed cleanup run", "missed", missed)
+			r.updateCondition(janitorPolicy, ConditionTypeMissedSchedule, metav1.ConditionFalse, ReasonCaughtUp, message)
+			r.Recorder.Event(janitorPolicy, EventTypeNormal, ReasonCaughtUp, message)
+			go r.runScheduledCleanup(types.NamespacedName{Name: janitorPolicy.Name, Namespace: janitorPolicy.Namespace}, janitorPolicy.UID)
+			return
+		}
+		missed = schedule.Next(lastRun)
+	}
+
+	message := fmt.Sprintf("Run scheduled at %s was missed and is past the starting deadline", missed.UTC().Format(time.RFC3339))
+	log.Info("Missed cleanup run", "missed", missed)
+	r.updateCondition(janitorPolicy, ConditionTypeMissedSchedule, metav1.ConditionTrue, ReasonDeadlineExceeded, message)
+	r.Recorder.Event(janitorPolicy, EventTypeWarning, ReasonDeadlineExceeded, message)
+}
+
+// policySchedule returns the schedule of the policy including the operator-wide spread and the policy jitter
+func (r *JanitorPolicyReconciler) policySchedule(janitorPolicy *opsv1alpha1.JanitorPolicy) (cron.Schedule, error) {
+	schedule, err := parseSchedule(janitorPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	var jitter time.Duration
+	if janitorPolicy.Spec.Jitter != "" {
+		jitter, err = time.ParseDuration(janitorPolicy.Spec.Jitter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jitter: %w", err)
+		}
+	}
+
+	return newSpreadSchedule(schedule, string(janitorPolicy.UID), r.ScheduleSpread, jitter), nil
+}
+
+// parseSchedule parses the policy schedule and evaluates it in the policy time zone
+func parseSchedule(janitorPolicy *opsv1alpha1.JanitorPolicy) (cron.Schedule, error) {
+	schedule, err := scheduleParser.Parse(janitorPolicy.Spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron schedule: %w", err)
+	}
+
+	// Policies stored before the CRD validated the interval would otherwise run every second
+	if _, ok := schedule.(cron.ConstantDelaySchedule); ok && !everyPattern.MatchString(janitorPolicy.Spec.Schedule) {
+		return nil, fmt.Errorf("invalid cron schedule: @every interval must be at least 1m and given in whole hours, minutes and seconds")
+	}
+
+	location := time.UTC
+	if janitorPolicy.Spec.TimeZone != "" {
+		location, err = time.LoadLocation(janitorPolicy.Spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", janitorPolicy.Spec.TimeZone, err)
+		}
+	}
+
+	// Interval schedules such as @every do not depend on the time zone
+	if specSchedule, ok := schedule.(*cron.SpecSchedule); ok {
+		specSchedule.Location = location
+	}
+
+	// Expressions such as 0 0 31 2 * are valid but have no activation
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron schedule %q never fires", janitorPolicy.Spec.Schedule)
+	}
+	return schedule, nil
+}
+
+// removeFromScheduler removes the cleanup job of the policy from the scheduler
+func (r *JanitorPolicyReconciler) removeFromScheduler(janitorPolicy *opsv1alpha1.JanitorPolicy) {
+	r.entriesMu.Lock()
+	defer r.entriesMu.Unlock()
+
+	if entry, found := r.entries[janitorPolicy.UID]; found {
+		r.cronScheduler.Remove(entry.EntryID)
+		delete(r.entries, janitorPolicy.UID)
+		r.Log.Info("Removed from scheduler", "janitorpolicy", janitorPolicy.Name, "entryID", entry.EntryID)
+	}
+}
+
+// removeFromSchedulerByName removes the cleanup jobs of a policy that no longer exists
+func (r *JanitorPolicyReconciler) removeFromSchedulerByName(key types.NamespacedName) {
+	r.entriesMu.Lock()
+	defer r.entriesMu.Unlock()
+
+	for uid, entry := range r.entries {
+		if entry.Name == key {
+			r.cronScheduler.Remove(entry.EntryID)
+			delete(r.entries, uid)
+			r.Log.Info("Removed from scheduler", "janitorpolicy", key.Name, "entryID", entry.EntryID)
+		}
+	}
+}
+
+// runScheduledCleanup fetches the latest version of the policy and executes its cleanup
+func (r *JanitorPolicyReconciler) runScheduledCleanup(key types.NamespacedName, uid types.UID) {
+	// Runs are cancelled when leadership is lost
+	r.entriesMu.Lock()
+	ctx := r.schedulerCtx
+	r.entriesMu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	log := r.Log.WithValues("janitorpolicy", key.Name)
+
+	var janitorPolicy opsv1alpha1.JanitorPolicy
+	if err := r.Get(ctx, key, &janitorPolicy); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("JanitorPolicy no longer exists, skipping scheduled cleanup")
+			return
+		}
+		log.Error(err, "Failed to get JanitorPolicy for scheduled cleanup")
+		return
+	}
+
+	// A policy recreated with the same name gets its own entry
+	if janitorPolicy.UID != uid || janitorPolicy.DeletionTimestamp != nil {
+		log.Info("JanitorPolicy was replaced or is being deleted, skipping scheduled cleanup")
+		return
+	}
+
+	// The entry may fire before the reconciler has processed the suspension
+	if janitorPolicy.Spec.Suspend {
+		log.Info("JanitorPolicy is suspended, skipping scheduled cleanup")
+		return
+	}
+
+	run, started := r.startRun(ctx, &janitorPolicy)
+	if !started {
+		log.Info("Previous cleanup run is still in progress, skipping", "concurrencyPolicy", janitorPolicy.Spec.ConcurrencyPolicy)
+		r.recordSkippedRun(ctx, key)
+		return
+	}
+	defer r.finishRun(janitorPolicy.UID, run)
+
+	r.executeCleanup(run.ctx, &janitorPolicy)
+}
+
+// startRun registers a cleanup run for the policy according to its concurrency policy.
+// It returns false if the run must be skipped because another run is in progress.
+func (r *JanitorPolicyReconciler) startRun(ctx context.Context, janitorPolicy *opsv1alpha1.JanitorPolicy) (*activeRun, bool) {
+	for {
+		r.runsMu.Lock()
+		running := r.runs[janitorPolicy.UID]
+		if len(running) == 0 || janitorPolicy.Spec.ConcurrencyPolicy == ConcurrencyPolicyAllow {
+			runCtx, cancel := context.WithCancel(ctx)
+			run := &activeRun{ctx: runCtx, cancel: cancel, done: make(chan struct{})}
+			r.runs[janitorPolicy.UID] = append(running, run)
+			r.runsMu.Unlock()
+			return run, true
+		}
+
+		if janitorPolicy.Spec.ConcurrencyPolicy != ConcurrencyPolicyReplace {
+			r.runsMu.Unlock()
+			return nil, false
+		}
+
+		// Cancel the runs in progress and wait for them to wind down. Another run may have
+		// registered by then, so the runs are checked again until none is left.
+		r.Log.Info("Cancelling cleanup run in progress", "janitorpolicy", janitorPolicy.Name)
+		for _, previous := range running {
+			previous.cancel()
+		}
+		previous := running[0]
+		r.runsMu.Unlock()
+		<-previous.done
+	}
+}
+
+// finishRun unregisters a completed cleanup run
+func (r *JanitorPolicyReconciler) finishRun(uid types.UID, run *activeRun) {
+	r.runsMu.Lock()
+	defer r.runsMu.Unlock()
+
+	run.cancel()
+	close(run.done)
+	r.runs[uid] = slices.DeleteFunc(r.runs[uid], func(active *activeRun) bool { return active == run })
+	if len(r.runs[uid]) == 0 {
+		delete(r.runs, uid)
+	}
+}
+
+// recordSkippedRun records a skipped run in the policy status and metrics
+func (r *JanitorPolicyReconciler) recordSkippedRun(ctx context.Context, key types.NamespacedName) {
+	log := r.Log.WithValues("janitorpolicy", key.Name)
+
+	if r.metricsServer != nil {
+		r.metricsServer.RecordSkippedRun(key.Namespace, key.Name, "ConcurrentRun")
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var janitorPolicy opsv1alpha1.JanitorPolicy
+		if err := r.Get(ctx, key, &janitorPolicy); err != nil {
+			return err
+		}
+
+		now := metav1.Now()
+		janitorPolicy.Status.SkippedRuns++
+		janitorPolicy.Status.LastSkippedRun = &now
+		r.Recorder.Event(&janitorPolicy, EventTypeWarning, ReasonRunSkipped, "Cleanup run skipped because the previous run is still in progress")
+		return r.Status().Update(ctx, &janitorPolicy)
+	})
+	if err != nil {
+		log.Error(err, "Failed to record skipped run")
+	}
 }
 
 // executeCleanup executes the cleanup operation
@@ -204,50 +643,81 @@ func (r *JanitorPolicyReconciler) executeCleanup(ctx context.Context, janitorPol
 		DryRun:        janitorPolicy.Spec.DryRun,
 		Logger:        log,
 		EventRecorder: r.Recorder,
+		Notifier:      r.notifier,
 	}
 
 	// Execute cleanup
 	stats, err := r.cleanupEngine.Execute(ctx, cleanupCtx)
 	duration := time.Since(startTime)
 
-	// Update policy status
-	var updatedPolicy opsv1alpha1.JanitorPolicy
-	if err := r.Get(ctx, types.NamespacedName{Name: janitorPolicy.Name, Namespace: janitorPolicy.Namespace}, &updatedPolicy); err != nil {
-		log.Error(err, "Failed to get JanitorPolicy for status update")
-		return
+	// Record the outcome even if the run was cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	stats.Duration = duration.String()
+	message := fmt.Sprintf("Cleanup completed successfully. Resources scanned: %d, cleaned: %d",
+		stats.ResourcesScanned, stats.ResourcesCleaned)
+	if stats.DowngradedToDryRun {
+		message += " (dry-run, outside maintenance windows)"
+	}
+	if stats.BudgetExceeded != "" {
+		message += " (not applied, deletion budget exceeded)"
+	}
+	if err != nil {
+		message = fmt.Sprintf("Cleanup failed: %v", err)
 	}
 
-	// Update status
+	// Update policy status, retrying on conflicts with reconciles that ran meanwhile
 	now := metav1.Now()
-	updatedPolicy.Status.LastRun = &now
-	updatedPolicy.Status.Stats = stats
-	updatedPolicy.Status.Stats.Duration = duration.String()
+	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var updatedPolicy opsv1alpha1.JanitorPolicy
+		if getErr := r.Get(ctx, types.NamespacedName{Name: janitorPolicy.Name, Namespace: janitorPolicy.Namespace}, &updatedPolicy); getErr != nil {
+			return getErr
+		}
 
-	if err != nil {
-		updatedPolicy.Status.Message = fmt.Sprintf("Cleanup failed: %v", err)
-		r.updateCondition(&updatedPolicy, ConditionTypeReady, metav1.ConditionFalse, ReasonFailed, err.Error())
-		r.Recorder.Event(&updatedPolicy, EventTypeWarning, ReasonFailed, fmt.Sprintf("Cleanup failed: %v", err))
-	} else {
-		updatedPolicy.Status.Message = fmt.Sprintf("Cleanup completed successfully. Resources scanned: %d, cleaned: %d",
-			stats.ResourcesScanned, stats.ResourcesCleaned)
-		r.updateCondition(&updatedPolicy, ConditionTypeReady, metav1.ConditionTrue, ReasonSucceeded, "Cleanup completed successfully")
-		r.Recorder.Event(&updatedPolicy, EventTypeNormal, ReasonSucceeded,
-			fmt.Sprintf("Cleanup completed. Scanned: %d, Cleaned: %d", stats.ResourcesScanned, stats.ResourcesCleaned))
-	}
+		updatedPolicy.Status.LastRun = &now
+		updatedPolicy.Status.Stats = stats
+		updatedPolicy.Status.Message = message
+		if err != nil {
+			r.updateCondition(&updatedPolicy, ConditionTypeReady, metav1.ConditionFalse, ReasonFailed, err.Error())
+		} else {
+			r.updateCondition(&updatedPolicy, ConditionTypeReady, metav1.ConditionTrue, ReasonSucceeded, "Cleanup completed successfully")
+		}
 
-	// Calculate next run
-	if updatedPolicy.Spec.Schedule != "" {
-		if schedule, parseErr := cron.ParseStandard(updatedPolicy.Spec.Schedule); parseErr == nil {
-			nextRun := schedule.Next(time.Now())
-			updatedPolicy.Status.NextRun = &metav1.Time{Time: nextRun}
+		// The budget condition reflects the plan of the last run
+		switch {
+		case stats.BudgetExceeded != "":
+			r.updateCondition(&updatedPolicy, ConditionTypeBudgetExceeded, metav1.ConditionTrue, ReasonBudgetExceeded, stats.BudgetExceeded)
+		case updatedPolicy.Spec.Budget != nil:
+			r.updateCondition(&updatedPolicy, ConditionTypeBudgetExceeded, metav1.ConditionFalse, ReasonWithinBudget, "The plan of the last run was within the deletion budget")
+		default:
+			meta.RemoveStatusCondition(&updatedPolicy.Status.Conditions, ConditionTypeBudgetExceeded)
 		}
-	}
 
-	// Update status
-	if updateErr := r.Status().Update(ctx, &updatedPolicy); updateErr != nil {
+		// A completed run resolves an earlier missed schedule
+		if missed := meta.FindStatusCondition(updatedPolicy.Status.Conditions, ConditionTypeMissedSchedule); missed != nil && missed.Status == metav1.ConditionTrue {
+			r.updateCondition(&updatedPolicy, ConditionTypeMissedSchedule, metav1.ConditionFalse, ReasonSucceeded, "A cleanup run has completed since the missed schedule")
+		}
+
+		// Calculate next run
+		if updatedPolicy.Spec.Schedule != "" {
+			if schedule, parseErr := r.policySchedule(&updatedPolicy); parseErr == nil {
+				updatedPolicy.Status.NextRun = nextRunTime(schedule, time.Now())
+			}
+		}
+
+		return r.Status().Update(ctx, &updatedPolicy)
+	})
+	if updateErr != nil {
 		log.Error(updateErr, "Failed to update status after cleanup")
 	}
 
+	if err != nil {
+		r.Recorder.Event(janitorPolicy, EventTypeWarning, ReasonFailed, message)
+	} else {
+		r.Recorder.Event(janitorPolicy, EventTypeNormal, ReasonSucceeded,
+			fmt.Sprintf("Cleanup completed. Scanned: %d, Cleaned: %d", stats.ResourcesScanned, stats.ResourcesCleaned))
+	}
+
 	// Update metrics
 	if r.metricsServer != nil {
 		r.metricsServer.RecordCleanupMetrics(stats, err)
@@ -272,13 +742,14 @@ func (r *JanitorPolicyReconciler) updateCondition(janitorPolicy *opsv1alpha1.Jan
 		LastTransitionTime: metav1.Now(),
 	}
 
-	// Update or add condition
+	// Update or add condition, keeping the transition time while the status is unchanged
 	found := false
 	for i, existingCondition := range janitorPolicy.Status.Conditions {
 		if existingCondition.Type == conditionType {
-			if existingCondition.Status != status {
-				janitorPolicy.Status.Conditions[i] = condition
+			if existingCondition.Status == status {
+				condition.LastTransitionTime = existingCondition.LastTransitionTime
 			}
+			janitorPolicy.Status.Conditions[i] = condition
 			found = true
 			break
 		}
@@ -291,19 +762,29 @@ func (r *JanitorPolicyReconciler) updateCondition(janitorPolicy *opsv1alpha1.Jan
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *JanitorPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// Initialize cron scheduler
-	r.cronScheduler = cron.New(cron.WithSeconds())
-	r.cronScheduler.Start()
+	// Initialize cron scheduler, started by the leader only
+	r.cronScheduler = cron.New(cron.WithParser(scheduleParser))
+	if err := mgr.Add(&leaderScheduler{reconciler: r}); err != nil {
+		return err
+	}
+	r.entries = make(map[types.UID]scheduledEntry)
+	r.missedChecked = make(map[types.UID]bool)
+	r.runs = make(map[types.UID][]*activeRun)
+	r.resync = make(chan event.GenericEvent)
 
 	// Initialize cleanup engine
-	r.cleanupEngine = cleanup.NewEngine()
+	r.cleanupEngine = cleanup.NewEngine(cleanup.NewWorkerPool(r.CleanupParallelism, r.CleanupQPS, r.CleanupBurst))
 
 	// Initialize metrics server
 	r.metricsServer = metrics.NewServer()
 
+	// Initialize notifier
+	r.notifier = notification.NewNotifier()
+
 	// Set up the controller
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&opsv1alpha1.JanitorPolicy{}).
 		Owns(&corev1.Event{}).
+		WatchesRawSource(&source.Channel{Source: r.resync}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
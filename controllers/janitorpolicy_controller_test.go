@@ -0,0 +1,719 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	crdFile   = "../config/crd/bases/janitor.io_janitorpolicies.yaml"
+	typesFile = "../api/v1alpha1/janitorpolicy_types.go"
+)
+
+// scheduleValidation is a CEL validation rule of the schedule field
+type scheduleValidation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// crdScheduleValidations returns the validation rules of spec.schedule in the CRD
+func crdScheduleValidations(t *testing.T) []scheduleValidation {
+	t.Helper()
+
+	data, err := os.ReadFile(crdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Schema struct {
+					OpenAPIV3Schema struct {
+						Properties struct {
+							Spec struct {
+								Properties struct {
+									Schedule struct {
+										Validations []scheduleValidation `json:"x-kubernetes-validations"`
+									} `json:"schedule"`
+								} `json:"properties"`
+							} `json:"spec"`
+						} `json:"properties"`
+					} `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		t.Fatal(err)
+	}
+	if len(crd.Spec.Versions) != 1 {
+		t.Fatalf("expected one version in %s", crdFile)
+	}
+	return crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties.Spec.Properties.Schedule.Validations
+}
+
+// markerScheduleValidations returns the XValidation markers of the Schedule field in the API types
+func markerScheduleValidations(t *testing.T) []scheduleValidation {
+	t.Helper()
+
+	data, err := os.ReadFile(typesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	marker := regexp.MustCompile("^// \\+kubebuilder:validation:XValidation:rule=`(.*)`,message=\"(.*)\"$")
+
+	var validations []scheduleValidation
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Schedule string") {
+			return validations
+		}
+		if match := marker.FindStringSubmatch(line); match != nil {
+			validations = append(validations, scheduleValidation{Rule: match[1], Message: match[2]})
+		} else if !strings.HasPrefix(line, "//") {
+			validations = nil
+		}
+	}
+	t.Fatalf("Schedule field not found in %s", typesFile)
+	return nil
+}
+
+func TestScheduleValidationMatchesMarkers(t *testing.T) {
+	if crd, markers := crdScheduleValidations(t), markerScheduleValidations(t); !reflect.DeepEqual(crd, markers) {
+		t.Errorf("schedule validations of the CRD differ from the API types:\nCRD:     %+v\nmarkers: %+v", crd, markers)
+	}
+}
+
+func TestScheduleValidationMatchesParser(t *testing.T) {
+	var pattern *regexp.Regexp
+	for _, validation := range crdScheduleValidations(t) {
+		if expr, found := strings.CutPrefix(validation.Rule, `self.matches(r"`); found {
+			pattern = regexp.MustCompile(strings.TrimSuffix(expr, `")`))
+		}
+	}
+	if pattern == nil {
+		t.Fatal("schedule pattern not found in the CRD")
+	}
+
+	tests := []struct {
+		schedule string
+		valid    bool
+		// schedulerOnly schedules are accepted by the API server and reported in the Scheduled condition
+		schedulerOnly bool
+	}{
+		{schedule: "0 2 * * *", valid: true},
+		{schedule: "*/15 * * * *", valid: true},
+		{schedule: "*/05 * * * *", valid: true},
+		{schedule: "5/10 * * * *", valid: true},
+		{schedule: "0 9-17 * * mon-fri", valid: true},
+		{schedule: "0 9-17/2 * * MON-FRI", valid: true},
+		{schedule: "0 0 1,15 * *", valid: true},
+		{schedule: "30 4 * jan-mar,oct *", valid: true},
+		{schedule: "0 0 ? * sun", valid: true},
+		{schedule: "0 0 1 */2 *", valid: true},
+		{schedule: "59 23 31 12 6", valid: true},
+		{schedule: "0 0 * * 0-6", valid: true},
+		{schedule: "10-10 * * * *", valid: true},
+		{schedule: "@daily", valid: true},
+		{schedule: "@hourly", valid: true},
+		{schedule: "@yearly", valid: true},
+		{schedule: "@midnight", valid: true},
+		{schedule: "@every 1m", valid: true},
+		{schedule: "@every 6h", valid: true},
+		{schedule: "@every 1h30m", valid: true},
+		{schedule: "@every 1m30s", valid: true},
+
+		{schedule: ""},
+		{schedule: "* * * *"},
+		{schedule: "0 * * * * *"},
+		{schedule: "*/0 * * * *"},
+		{schedule: "0 0 * * mon-fri/0"},
+		{schedule: "60 * * * *"},
+		{schedule: "0 24 * * *"},
+		{schedule: "0 0 0 * *"},
+		{schedule: "0 0 32 * *"},
+		{schedule: "0 0 * 13 *"},
+		{schedule: "0 0 * * 7"},
+		{schedule: "0 0 * * funday"},
+		{schedule: "@reboot"},
+		{schedule: "@every 30s"},
+		{schedule: "@every 0m"},
+		{schedule: "@every 500ms"},
+		{schedule: "@every 1.5h"},
+		{schedule: "30-10 * * * *", schedulerOnly: true},
+		{schedule: "0 22-2 * * *", schedulerOnly: true},
+		{schedule: "0 0 * * fri-mon", schedulerOnly: true},
+		{schedule: "0 0 * dec-jan *", schedulerOnly: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			if matches := pattern.MatchString(tt.schedule); matches != (tt.valid || tt.schedulerOnly) {
+				t.Errorf("CRD accepts %q: %v, want %v", tt.schedule, matches, tt.valid || tt.schedulerOnly)
+			}
+
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Schedule = tt.schedule
+			if _, err := parseSchedule(policy); (err == nil) != tt.valid {
+				t.Errorf("scheduler accepts %q: %v (%v), want %v", tt.schedule, err == nil, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		timeZone string
+		// now defaults to 2024-06-03T12:00:00Z
+		now string
+		// wantNext lists the next activations in UTC
+		wantNext []string
+		wantErr  bool
+	}{
+		{name: "UTC by default", schedule: "0 2 * * *", wantNext: []string{"2024-06-04T02:00:00Z"}},
+		{name: "time zone", schedule: "0 2 * * *", timeZone: "Europe/Berlin", wantNext: []string{"2024-06-04T00:00:00Z"}},
+		{name: "interval ignores time zone", schedule: "@every 90m", timeZone: "Asia/Tokyo", wantNext: []string{"2024-06-03T13:30:00Z"}},
+
+		// Clocks spring forward from 02:00 to 03:00 local time: runs in the skipped hour are skipped that day
+		{
+			name: "New York before spring forward", schedule: "0 3 * * *", timeZone: "America/New_York", now: "2024-03-10T06:00:00Z",
+			wantNext: []string{"2024-03-10T07:00:00Z", "2024-03-11T07:00:00Z"},
+		},
+		{
+			name: "New York in the skipped hour", schedule: "30 2 * * *", timeZone: "America/New_York", now: "2024-03-10T06:00:00Z",
+			wantNext: []string{"2024-03-11T06:30:00Z", "2024-03-12T06:30:00Z"},
+		},
+		{
+			name: "Berlin before spring forward", schedule: "0 3 * * *", timeZone: "Europe/Berlin", now: "2024-03-30T23:00:00Z",
+			wantNext: []string{"2024-03-31T01:00:00Z", "2024-04-01T01:00:00Z"},
+		},
+		{
+			name: "Berlin in the skipped hour", schedule: "30 2 * * *", timeZone: "Europe/Berlin", now: "2024-03-30T23:00:00Z",
+			wantNext: []string{"2024-04-01T00:30:00Z", "2024-04-02T00:30:00Z"},
+		},
+
+		// Clocks fall back and repeat an hour: runs in the repeated hour run at both occurrences
+		{
+			name: "New York in the repeated hour", schedule: "30 1 * * *", timeZone: "America/New_York", now: "2024-11-03T04:00:00Z",
+			wantNext: []string{"2024-11-03T05:30:00Z", "2024-11-03T06:30:00Z", "2024-11-04T06:30:00Z"},
+		},
+		{
+			name: "New York hourly across fall back", schedule: "0 * * * *", timeZone: "America/New_York", now: "2024-11-03T05:30:00Z",
+			wantNext: []string{"2024-11-03T06:00:00Z", "2024-11-03T07:00:00Z"},
+		},
+		{
+			name: "Berlin in the repeated hour", schedule: "30 2 * * *", timeZone: "Europe/Berlin", now: "2024-10-26T23:00:00Z",
+			wantNext: []string{"2024-10-27T00:30:00Z", "2024-10-27T01:30:00Z", "2024-10-28T01:30:00Z"},
+		},
+		{
+			name: "Berlin after fall back", schedule: "0 3 * * *", timeZone: "Europe/Berlin", now: "2024-10-26T23:00:00Z",
+			wantNext: []string{"2024-10-27T02:00:00Z", "2024-10-28T02:00:00Z"},
+		},
+
+		{name: "invalid time zone", schedule: "0 2 * * *", timeZone: "Mars/Olympus", wantErr: true},
+		{name: "interval stored before validation", schedule: "@every 1s", wantErr: true},
+		{name: "never fires", schedule: "0 0 31 2 *", wantErr: true},
+		{name: "never fires in any month", schedule: "0 0 30 feb *", wantErr: true},
+		{name: "reversed range", schedule: "30-10 * * * *", wantErr: true},
+		{name: "reversed hour range", schedule: "0 22-2 * * *", wantErr: true},
+		{name: "reversed weekday range", schedule: "0 0 * * fri-mon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Schedule = tt.schedule
+			policy.Spec.TimeZone = tt.timeZone
+
+			schedule, err := parseSchedule(policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+			if tt.now != "" {
+				if now, err = time.Parse(time.RFC3339, tt.now); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var next []string
+			for range tt.wantNext {
+				now = schedule.Next(now)
+				next = append(next, now.UTC().Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(next, tt.wantNext) {
+				t.Errorf("next runs at %v, want %v", next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestReconcileReportsUnschedulablePolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    string
+		wantMessage string
+	}{
+		{name: "reversed range", schedule: "0 22-2 * * *", wantMessage: "beyond end of range"},
+		{name: "never fires", schedule: "0 0 31 2 *", wantMessage: "never fires"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default", UID: "policy-uid", Finalizers: []string{FinalizerName}},
+				Spec:       opsv1alpha1.JanitorPolicySpec{Schedule: tt.schedule},
+				Status:     opsv1alpha1.JanitorPolicyStatus{Phase: PhaseActive},
+			}
+			r := newTestReconciler(policy)
+			key := types.NamespacedName{Name: "policy", Namespace: "default"}
+
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err == nil {
+				t.Fatal("expected an error for an unschedulable policy")
+			}
+			if _, scheduled := r.entries[policy.UID]; scheduled {
+				t.Error("unschedulable policy was added to the scheduler")
+			}
+
+			var updated opsv1alpha1.JanitorPolicy
+			if err := r.Get(context.Background(), key, &updated); err != nil {
+				t.Fatal(err)
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeScheduled)
+			if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != ReasonFailed {
+				t.Fatalf("Scheduled condition %+v, want False with reason %s", condition, ReasonFailed)
+			}
+			if !strings.Contains(condition.Message, tt.wantMessage) {
+				t.Errorf("condition message %q does not mention %q", condition.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestCheckMissedRun(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		created  string
+		lastRun  string
+		now      string
+		deadline *int64
+		spread   time.Duration
+		jitter   string
+		// wantStatus is the MissedSchedule condition status, empty if the condition is not set
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:    "nothing missed",
+			created: "2024-06-01T00:00:00Z", lastRun: "2024-06-03T12:10:00Z", now: "2024-06-03T12:30:00Z",
+		},
+		{
+			name:    "missed within the deadline",
+			created: "2024-06-01T00:00:00Z", lastRun: "2024-06-03T10:10:00Z", now: "2024-06-03T12:30:00Z",
+			deadline:   ptr.To[int64](3600),
+			wantStatus: metav1.ConditionFalse, wantReason: ReasonCaughtUp,
+		},
+		{
+			name:    "missed past the deadline",
+			created: "2024-06-01T00:00:00Z", lastRun: "2024-06-03T10:10:00Z", now: "2024-06-03T12:30:00Z",
+			deadline:   ptr.To[int64](600),
+			wantStatus: metav1.ConditionTrue, wantReason: ReasonDeadlineExceeded,
+		},
+		{
+			name:    "missed without a deadline",
+			created: "2024-06-01T00:00:00Z", lastRun: "2024-06-03T10:10:00Z", now: "2024-06-03T12:30:00Z",
+			wantStatus: metav1.ConditionTrue, wantReason: ReasonDeadlineExceeded,
+		},
+		{
+			name:    "never ran and created after the last activation",
+			created: "2024-06-03T12:10:00Z", now: "2024-06-03T12:30:00Z",
+			deadline: ptr.To[int64](3600),
+		},
+		{
+			name:    "never ran and created before the last activation",
+			created: "2024-06-03T11:50:00Z", now: "2024-06-03T12:30:00Z",
+			deadline:   ptr.To[int64](3600),
+			wantStatus: metav1.ConditionFalse, wantReason: ReasonCaughtUp,
+		},
+		{
+			// Activations are delayed by less than 40m, so the 12:00 run is due by 12:40 whatever the offset
+			name:    "spread and jittered run missed within the deadline",
+			created: "2024-06-01T00:00:00Z", lastRun: "2024-06-03T11:45:00Z", now: "2024-06-03T12:45:00Z",
+			deadline: ptr.To[int64](3600), spread: 30 * time.Minute, jitter: "10m",
+			wantStatus: metav1.ConditionFalse, wantReason: ReasonCaughtUp,
+		},
+		{
+			name:    "spread and jittered run missed past the deadline",
+			created: "2024-06-01T00:00:00Z", lastRun: "2024-06-03T11:45:00Z", now: "2024-06-03T12:45:00Z",
+			deadline: ptr.To[int64](60), spread: 30 * time.Minute, jitter: "10m",
+			wantStatus: metav1.ConditionTrue, wantReason: ReasonDeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The policy is not stored, so a started run finds nothing to clean up
+			policy := &opsv1alpha1.JanitorPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default", UID: "policy-uid", CreationTimestamp: metav1.NewTime(at(tt.created))},
+				Spec:       opsv1alpha1.JanitorPolicySpec{Schedule: "0 * * * *", StartingDeadlineSeconds: tt.deadline, Jitter: tt.jitter},
+			}
+			if tt.lastRun != "" {
+				policy.Status.LastRun = &metav1.Time{Time: at(tt.lastRun)}
+			}
+			r := newTestReconciler()
+			r.ScheduleSpread = tt.spread
+
+			schedule, err := r.policySchedule(policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.checkMissedRun(policy, schedule, at(tt.now))
+
+			condition := meta.FindStatusCondition(policy.Status.Conditions, ConditionTypeMissedSchedule)
+			switch {
+			case tt.wantStatus == "" && condition != nil:
+				t.Errorf("unexpected MissedSchedule condition %+v", condition)
+			case tt.wantStatus != "" && (condition == nil || condition.Status != tt.wantStatus || condition.Reason != tt.wantReason):
+				t.Errorf("MissedSchedule condition %+v, want %s with reason %s", condition, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestExecuteCleanupRetriesStatusConflicts(t *testing.T) {
+	policy := &opsv1alpha1.JanitorPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default", UID: "policy-uid"},
+		Spec:       opsv1alpha1.JanitorPolicySpec{DryRun: true},
+	}
+	r := newTestReconciler()
+
+	// The first status update conflicts with a reconcile that changed the policy meanwhile
+	conflicts := 1
+	r.Client = fake.NewClientBuilder().
+		WithScheme(r.Scheme).
+		WithObjects(policy.DeepCopy()).
+		WithStatusSubresource(&opsv1alpha1.JanitorPolicy{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				if conflicts > 0 {
+					conflicts--
+					return apierrors.NewConflict(opsv1alpha1.GroupVersion.WithResource("janitorpolicies").GroupResource(), obj.GetName(), errors.New("the object has been modified"))
+				}
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	r.executeCleanup(context.Background(), policy)
+	if conflicts > 0 {
+		t.Fatal("status was not updated")
+	}
+
+	var updated opsv1alpha1.JanitorPolicy
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "policy", Namespace: "default"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.LastRun == nil || updated.Status.Stats == nil {
+		t.Fatalf("run was not recorded after a conflict: %+v", updated.Status)
+	}
+	if condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeReady); condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("Ready condition %+v, want True", condition)
+	}
+}
+
+func TestStartRun(t *testing.T) {
+	tests := []struct {
+		concurrencyPolicy string
+		wantStarted       bool
+		wantCancelled     bool
+		wantRuns          int
+	}{
+		{concurrencyPolicy: ConcurrencyPolicyForbid, wantRuns: 1},
+		{concurrencyPolicy: ConcurrencyPolicyAllow, wantStarted: true, wantRuns: 2},
+		{concurrencyPolicy: ConcurrencyPolicyReplace, wantStarted: true, wantCancelled: true, wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.concurrencyPolicy, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", UID: "policy-uid"}}
+			policy.Spec.ConcurrencyPolicy = tt.concurrencyPolicy
+			r := newTestReconciler()
+
+			first, started := r.startRun(context.Background(), policy)
+			if !started {
+				t.Fatal("first run was not started")
+			}
+
+			type result struct {
+				run     *activeRun
+				started bool
+			}
+			results := make(chan result, 1)
+			go func() {
+				run, started := r.startRun(context.Background(), policy)
+				results <- result{run, started}
+			}()
+
+			// A replacing run waits for the cancelled run to wind down
+			if tt.wantCancelled {
+				<-first.ctx.Done()
+				select {
+				case <-results:
+					t.Fatal("run started before the cancelled run finished")
+				case <-time.After(50 * time.Millisecond):
+				}
+				r.finishRun(policy.UID, first)
+			}
+
+			second := <-results
+			if second.started != tt.wantStarted {
+				t.Errorf("second run started %v, want %v", second.started, tt.wantStarted)
+			}
+			if cancelled := first.ctx.Err() != nil; cancelled != tt.wantCancelled {
+				t.Errorf("first run cancelled %v, want %v", cancelled, tt.wantCancelled)
+			}
+			if runs := len(r.runs[policy.UID]); runs != tt.wantRuns {
+				t.Errorf("%d runs tracked, want %d", runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestStartRunReplacesOneRunAtATime(t *testing.T) {
+	policy := &opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", UID: "policy-uid"}}
+	policy.Spec.ConcurrencyPolicy = ConcurrencyPolicyReplace
+	r := newTestReconciler()
+
+	first, _ := r.startRun(context.Background(), policy)
+
+	// Two runs wait for the same run to wind down
+	results := make(chan *activeRun, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			run, _ := r.startRun(context.Background(), policy)
+			results <- run
+		}()
+	}
+	<-first.ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	r.finishRun(policy.UID, first)
+
+	// The run registered first is replaced by the other one, which only starts once it finished
+	replaced := <-results
+	<-replaced.ctx.Done()
+	select {
+	case <-results:
+		t.Fatal("run started before the replaced run finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	r.finishRun(policy.UID, replaced)
+
+	last := <-results
+	if last.ctx.Err() != nil {
+		t.Error("last run was cancelled")
+	}
+	if runs := r.runs[policy.UID]; len(runs) != 1 || runs[0] != last {
+		t.Errorf("tracked runs %v, want only the last run", runs)
+	}
+
+	r.finishRun(policy.UID, last)
+	if _, tracked := r.runs[policy.UID]; tracked {
+		t.Error("finished runs are still tracked")
+	}
+}
+
+func TestStartRunForbidsAfterOverlappingRuns(t *testing.T) {
+	policy := &opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", UID: "policy-uid"}}
+	policy.Spec.ConcurrencyPolicy = ConcurrencyPolicyAllow
+	r := newTestReconciler()
+
+	first, _ := r.startRun(context.Background(), policy)
+	second, _ := r.startRun(context.Background(), policy)
+
+	// The policy is switched to Forbid while the earlier of two overlapping runs is still in progress
+	r.finishRun(policy.UID, second)
+	policy.Spec.ConcurrencyPolicy = ConcurrencyPolicyForbid
+	if _, started := r.startRun(context.Background(), policy); started {
+		t.Error("run started while an overlapping run was in progress")
+	}
+
+	r.finishRun(policy.UID, first)
+	if _, started := r.startRun(context.Background(), policy); !started {
+		t.Error("run was not started after all runs finished")
+	}
+}
+
+// recordedEvents drains the events recorded so far by a fake recorder
+func recordedEvents(r *JanitorPolicyReconciler) []string {
+	recorder := r.Recorder.(*record.FakeRecorder)
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestReconcileRunNow(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		lastToken string
+		suspend   bool
+		wantRun   bool
+		wantToken string
+	}{
+		{name: "no token"},
+		{name: "new token", token: "1", wantRun: true, wantToken: "1"},
+		{name: "changed token", token: "2", lastToken: "1", wantRun: true, wantToken: "2"},
+		{name: "handled token", token: "1", lastToken: "1", wantToken: "1"},
+		{name: "token received while suspended", token: "1", suspend: true, wantToken: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default", UID: "policy-uid", Finalizers: []string{FinalizerName}},
+				Spec:       opsv1alpha1.JanitorPolicySpec{DryRun: true, Suspend: tt.suspend},
+				Status:     opsv1alpha1.JanitorPolicyStatus{Phase: PhaseActive, LastRunNowToken: tt.lastToken},
+			}
+			if tt.token != "" {
+				policy.Annotations = map[string]string{RunNowAnnotation: tt.token}
+			}
+			r := newTestReconciler(policy)
+			key := types.NamespacedName{Name: "policy", Namespace: "default"}
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			triggered := false
+			for _, event := range recordedEvents(r) {
+				if strings.HasPrefix(event, EventTypeNormal+" "+ReasonRunNow+" ") {
+					triggered = true
+				}
+			}
+			if triggered != tt.wantRun {
+				t.Errorf("run triggered %v, want %v", triggered, tt.wantRun)
+			}
+
+			// The run is started in the background and records its outcome in the status
+			var updated opsv1alpha1.JanitorPolicy
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				if err := r.Get(ctx, key, &updated); err != nil {
+					t.Fatal(err)
+				}
+				if !tt.wantRun || updated.Status.LastRun != nil || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if ran := updated.Status.LastRun != nil; ran != tt.wantRun {
+				t.Errorf("run recorded %v, want %v", ran, tt.wantRun)
+			}
+			if updated.Status.LastRunNowToken != tt.wantToken {
+				t.Errorf("last run-now token %q, want %q", updated.Status.LastRunNowToken, tt.wantToken)
+			}
+
+			// Reconciling again does not trigger the same token twice
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, event := range recordedEvents(r) {
+				if strings.HasPrefix(event, EventTypeNormal+" "+ReasonRunNow+" ") {
+					t.Errorf("token triggered again: %s", event)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleCleanupReplacesChangedEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(policy *opsv1alpha1.JanitorPolicy)
+		wantReplace bool
+	}{
+		{name: "unchanged", mutate: func(policy *opsv1alpha1.JanitorPolicy) {}},
+		{name: "unrelated change", mutate: func(policy *opsv1alpha1.JanitorPolicy) { policy.Spec.DryRun = false }},
+		{name: "schedule", mutate: func(policy *opsv1alpha1.JanitorPolicy) { policy.Spec.Schedule = "30 * * * *" }, wantReplace: true},
+		{name: "time zone", mutate: func(policy *opsv1alpha1.JanitorPolicy) { policy.Spec.TimeZone = "Europe/Berlin" }, wantReplace: true},
+		{name: "jitter", mutate: func(policy *opsv1alpha1.JanitorPolicy) { policy.Spec.Jitter = "5m" }, wantReplace: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default", UID: "policy-uid", CreationTimestamp: metav1.Now()},
+				Spec:       opsv1alpha1.JanitorPolicySpec{Schedule: "0 * * * *", DryRun: true},
+			}
+			r := newTestReconciler()
+			ctx := context.Background()
+
+			if err := r.scheduleCleanup(ctx, policy); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			original := r.entries[policy.UID].EntryID
+
+			updated := policy.DeepCopy()
+			updated.Status.NextRun = nil
+			tt.mutate(updated)
+			if err := r.scheduleCleanup(ctx, updated); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			entry := r.entries[updated.UID]
+			if replaced := entry.EntryID != original; replaced != tt.wantReplace {
+				t.Errorf("entry replaced %v, want %v", replaced, tt.wantReplace)
+			}
+			if entry.Schedule != updated.Spec.Schedule || entry.TimeZone != updated.Spec.TimeZone || entry.Jitter != updated.Spec.Jitter {
+				t.Errorf("entry %+v does not match the spec %+v", entry, updated.Spec)
+			}
+			cronEntries := r.cronScheduler.Entries()
+			if len(cronEntries) != 1 || cronEntries[0].ID != entry.EntryID {
+				t.Errorf("got cron entries %+v, want only entry %d", cronEntries, entry.EntryID)
+			}
+			if updated.Status.NextRun == nil {
+				t.Error("next run was not set")
+			}
+		})
+	}
+}
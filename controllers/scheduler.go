@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// leaderScheduler runs the cron entries of all policies only while this manager is the elected leader
+type leaderScheduler struct {
+	reconciler *JanitorPolicyReconciler
+}
+
+var _ manager.LeaderElectionRunnable = &leaderScheduler{}
+
+// NeedLeaderElection makes the manager start the scheduler only on the leader
+func (s *leaderScheduler) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the scheduler until leadership is lost or the manager stops
+func (s *leaderScheduler) Start(ctx context.Context) error {
+	r := s.reconciler
+	log := r.Log.WithName("scheduler")
+
+	r.entriesMu.Lock()
+	r.schedulerCtx = ctx
+	r.entriesMu.Unlock()
+
+	r.cronScheduler.Start()
+	log.Info("Scheduler started")
+
+	// Rebuild the entries from the current policies, including the missed run checks. The policies
+	// are enqueued rather than reconciled here, so they are serialized with the controller's workers.
+	var policies opsv1alpha1.JanitorPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		log.Error(err, "Failed to list JanitorPolicies")
+	}
+enqueue:
+	for i := range policies.Items {
+		select {
+		case r.resync <- event.GenericEvent{Object: &policies.Items[i]}:
+		case <-ctx.Done():
+			break enqueue
+		}
+	}
+
+	<-ctx.Done()
+
+	// Forget all entries so the next leader term rebuilds them
+	stopped := r.cronScheduler.Stop()
+	r.entriesMu.Lock()
+	for uid, entry := range r.entries {
+		r.cronScheduler.Remove(entry.EntryID)
+		delete(r.entries, uid)
+	}
+	r.missedChecked = make(map[types.UID]bool)
+	r.entriesMu.Unlock()
+	<-stopped.Done()
+
+	log.Info("Scheduler stopped")
+	return nil
+}
+
+// spreadSchedule delays every activation of a schedule by a fixed offset plus a jitter that is
+// derived from the activation time, so the delayed time is known in advance and shown as NextRun
+type spreadSchedule struct {
+	schedule cron.Schedule
+	seed     string
+	offset   time.Duration
+	jitter   time.Duration
+}
+
+// newSpreadSchedule offsets the schedule by a hash of the seed within spread and adds up to jitter per activation
+func newSpreadSchedule(schedule cron.Schedule, seed string, spread, jitter time.Duration) cron.Schedule {
+	if spread <= 0 && jitter <= 0 {
+		return schedule
+	}
+
+	var offset time.Duration
+	if spread > 0 {
+		offset = time.Duration(hashOf(seed) % uint64(spread)).Truncate(time.Second)
+	}
+	return &spreadSchedule{schedule: schedule, seed: seed, offset: offset, jitter: jitter}
+}
+
+// Next returns the first delayed activation after t
+func (s *spreadSchedule) Next(t time.Time) time.Time {
+	activation := s.schedule.Next(t.Add(-s.offset - s.jitter))
+	for !activation.IsZero() {
+		if delayed := activation.Add(s.delay(activation)); delayed.After(t) {
+			return delayed
+		}
+		activation = s.schedule.Next(activation)
+	}
+	return activation
+}
+
+// delay returns the total delay applied to an activation
+func (s *spreadSchedule) delay(activation time.Time) time.Duration {
+	if s.jitter <= 0 {
+		return s.offset
+	}
+	jitter := time.Duration(hashOf(s.seed+"/"+strconv.FormatInt(activation.Unix(), 10)) % uint64(s.jitter))
+	return s.offset + jitter.Truncate(time.Second)
+}
+
+// hashOf returns a stable hash of the value
+func hashOf(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return h.Sum64()
+}
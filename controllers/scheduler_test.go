@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestSpreadSchedule(t *testing.T) {
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule string
+		seed     string
+		spread   time.Duration
+		jitter   time.Duration
+	}{
+		{name: "spread", schedule: "0 * * * *", seed: "default/a", spread: 30 * time.Minute},
+		{name: "jitter", schedule: "0 * * * *", seed: "default/a", jitter: 10 * time.Minute},
+		{name: "spread and jitter", schedule: "0 * * * *", seed: "team/b", spread: 20 * time.Minute, jitter: 10 * time.Minute},
+		{name: "daily", schedule: "0 2 * * *", seed: "team/c", spread: time.Hour, jitter: 30 * time.Minute},
+		{name: "weekdays", schedule: "30 9 * * mon-fri", seed: "team/d", spread: 2 * time.Hour, jitter: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := scheduleParser.Parse(tt.schedule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spread := newSpreadSchedule(schedule, tt.seed, tt.spread, tt.jitter)
+			again := newSpreadSchedule(schedule, tt.seed, tt.spread, tt.jitter)
+
+			// Every activation is delayed by less than spread plus jitter, none is skipped. The
+			// schedules run at most every two maximum delays, so activations cannot overtake each other.
+			activation := schedule.Next(start)
+			previous := activation.Add(-tt.spread - tt.jitter)
+			for i := 0; i < 50; i++ {
+				next := spread.Next(previous)
+				if delay := next.Sub(activation); delay < 0 || delay >= tt.spread+tt.jitter {
+					t.Fatalf("activation %s delayed by %s, want below %s", activation, delay, tt.spread+tt.jitter)
+				}
+				if next.Truncate(time.Second) != next {
+					t.Errorf("activation %s delayed to fractional seconds %s", activation, next)
+				}
+				if repeated := again.Next(previous); !repeated.Equal(next) {
+					t.Fatalf("activation %s delayed to %s and %s by schedules with the same seed", activation, next, repeated)
+				}
+				previous = next
+				activation = schedule.Next(activation)
+			}
+		})
+	}
+}
+
+func TestSpreadScheduleDisabled(t *testing.T) {
+	schedule, err := scheduleParser.Parse("0 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spread := newSpreadSchedule(schedule, "default/a", 0, 0); spread != schedule {
+		t.Errorf("got %T, want the schedule unchanged", spread)
+	}
+}
+
+func TestSpreadScheduleSeeds(t *testing.T) {
+	schedule, err := scheduleParser.Parse("0 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	// Policies scheduled at the same time are spread over the window
+	runs := make(map[time.Time]bool)
+	for _, seed := range []string{"default/a", "default/b", "team/a", "team/b", "ops/nightly", "ops/weekly"} {
+		runs[newSpreadSchedule(schedule, seed, time.Hour, 0).Next(start)] = true
+	}
+	if len(runs) < 4 {
+		t.Errorf("6 policies spread over %d distinct start times", len(runs))
+	}
+}
+
+func TestSpreadScheduleNeverFires(t *testing.T) {
+	schedule, err := scheduleParser.Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := newSpreadSchedule(schedule, "default/a", time.Hour, time.Minute).Next(time.Now()); !next.IsZero() {
+		t.Errorf("got activation %s, want none", next)
+	}
+}
+
+func TestLeaderSchedulerTerms(t *testing.T) {
+	created := metav1.Now()
+	newPolicy := func(name string) *opsv1alpha1.JanitorPolicy {
+		return &opsv1alpha1.JanitorPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid"), CreationTimestamp: created},
+			Spec:       opsv1alpha1.JanitorPolicySpec{Schedule: "0 * * * *", DryRun: true},
+		}
+	}
+	r := newTestReconciler(newPolicy("a"), newPolicy("b"))
+	scheduler := &leaderScheduler{reconciler: r}
+
+	// Every leader term enqueues all policies and forgets their entries when it ends
+	for term := 1; term <= 2; term++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error)
+		go func() { stopped <- scheduler.Start(ctx) }()
+
+		var enqueued []string
+		for len(enqueued) < 2 {
+			select {
+			case e := <-r.resync:
+				enqueued = append(enqueued, e.Object.GetName())
+			case <-time.After(5 * time.Second):
+				cancel()
+				t.Fatalf("term %d: enqueued %v, want both policies", term, enqueued)
+			}
+		}
+		sort.Strings(enqueued)
+		if enqueued[0] != "a" || enqueued[1] != "b" {
+			t.Errorf("term %d: enqueued %v, want [a b]", term, enqueued)
+		}
+
+		for _, name := range enqueued {
+			if err := r.scheduleCleanup(ctx, newPolicy(name)); err != nil {
+				t.Fatalf("term %d: scheduling %s: %v", term, name, err)
+			}
+		}
+		if len(r.entries) != 2 || len(r.missedChecked) != 2 || len(r.cronScheduler.Entries()) != 2 {
+			t.Errorf("term %d: got %d entries, %d missed run checks and %d cron entries while leading, want 2 each",
+				term, len(r.entries), len(r.missedChecked), len(r.cronScheduler.Entries()))
+		}
+
+		cancel()
+		select {
+		case err := <-stopped:
+			if err != nil {
+				t.Fatalf("term %d: unexpected error: %v", term, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("term %d: scheduler did not stop", term)
+		}
+		if len(r.entries) != 0 || len(r.missedChecked) != 0 || len(r.cronScheduler.Entries()) != 0 {
+			t.Errorf("term %d: got %d entries, %d missed run checks and %d cron entries after losing leadership, want none",
+				term, len(r.entries), len(r.missedChecked), len(r.cronScheduler.Entries()))
+		}
+	}
+}
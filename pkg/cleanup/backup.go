@@ -0,0 +1,138 @@
+package cleanup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// BackupTypeLocal writes backups to a directory of the operator's filesystem
+	BackupTypeLocal = "local"
+
+	// runIDLayout names the backup directory of a run after its start time, followed by a random suffix
+	runIDLayout = "20060102-150405"
+
+	// clusterScopedDir holds the backups of cluster-scoped objects in place of a namespace
+	clusterScopedDir = "_cluster"
+)
+
+// backup writes the objects deleted by one run to
+// <location>/<policy namespace>/<policy>/<run-id>/<namespace>/<kind>/<name>.yaml
+type backup struct {
+	dir    string
+	scheme *runtime.Scheme
+}
+
+// newBackup prepares the backup of a run started at start, or returns nil if the policy does
+// not enable backups. Backup types other than local are not supported yet.
+func newBackup(policy *opsv1alpha1.JanitorPolicy, scheme *runtime.Scheme, start time.Time) (*backup, error) {
+	config := policy.Spec.BackupConfig
+	if config == nil || !config.Enabled {
+		return nil, nil
+	}
+	if config.Type != BackupTypeLocal {
+		return nil, fmt.Errorf("backup type %q is not supported", config.Type)
+	}
+	if config.Location == "" {
+		return nil, fmt.Errorf("backup location is not set")
+	}
+
+	return &backup{
+		dir:    filepath.Join(policyBackupDir(policy), start.UTC().Format(runIDLayout)+"-"+rand.String(5)),
+		scheme: scheme,
+	}, nil
+}
+
+// write stores the object as YAML without its managed fields and status and returns the file path
+func (b *backup) write(obj client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(obj, b.scheme)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine kind: %w", err)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert object: %w", err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	u.SetManagedFields(nil)
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	data, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize object: %w", err)
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = clusterScopedDir
+	}
+	dir := filepath.Join(b.dir, namespace, gvk.Kind)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Backups may contain secret data
+	path := filepath.Join(dir, obj.GetName()+".yaml")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return path, nil
+}
+
+// policyBackupDir returns the directory holding the runs of the policy. Policies with the same
+// name in different namespaces are kept apart, so the retention of one never prunes the other.
+func policyBackupDir(policy *opsv1alpha1.JanitorPolicy) string {
+	return filepath.Join(policy.Spec.BackupConfig.Location, policy.Namespace, policy.Name)
+}
+
+// sweepBackups removes the run directories of the policy older than its retention and returns
+// their number. Directories not named after a run are left alone. A retention of 0 keeps all runs.
+func sweepBackups(policy *opsv1alpha1.JanitorPolicy, now time.Time) (int, error) {
+	config := policy.Spec.BackupConfig
+	if config == nil || !config.Enabled || config.Type != BackupTypeLocal || config.Location == "" || config.RetentionDays <= 0 {
+		return 0, nil
+	}
+
+	policyDir := policyBackupDir(policy)
+	entries, err := os.ReadDir(policyDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := now.Add(-time.Duration(config.RetentionDays) * 24 * time.Hour)
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if len(name) < len(runIDLayout) || (len(name) > len(runIDLayout) && name[len(runIDLayout)] != '-') {
+			continue
+		}
+		started, err := time.Parse(runIDLayout, name[:len(runIDLayout)])
+		if err != nil || !started.Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(policyDir, name)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
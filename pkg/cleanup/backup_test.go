@@ -0,0 +1,220 @@
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testBackupPolicy returns a policy backing up to the location
+func testBackupPolicy(config *opsv1alpha1.BackupConfig) *opsv1alpha1.JanitorPolicy {
+	policy := &opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "team-a"}}
+	policy.Spec.BackupConfig = config
+	return policy
+}
+
+func TestNewBackup(t *testing.T) {
+	start := time.Date(2024, 6, 3, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		config  *opsv1alpha1.BackupConfig
+		wantDir bool
+		wantErr bool
+	}{
+		{name: "no configuration"},
+		{name: "disabled", config: &opsv1alpha1.BackupConfig{Type: BackupTypeLocal, Location: "/backups"}},
+		{name: "local", config: &opsv1alpha1.BackupConfig{Enabled: true, Type: BackupTypeLocal, Location: "/backups"}, wantDir: true},
+		{name: "unsupported type", config: &opsv1alpha1.BackupConfig{Enabled: true, Type: "s3", Location: "s3://bucket"}, wantErr: true},
+		{name: "no location", config: &opsv1alpha1.BackupConfig{Enabled: true, Type: BackupTypeLocal}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := newBackup(testBackupPolicy(tt.config), scheme.Scheme, start)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantDir {
+				if b != nil {
+					t.Errorf("got backup to %s, want none", b.dir)
+				}
+				return
+			}
+
+			prefix := "/backups/team-a/nightly/20240603-020000-"
+			if !strings.HasPrefix(b.dir, prefix) || len(b.dir) != len(prefix)+5 {
+				t.Errorf("got directory %s, want %s followed by a random suffix", b.dir, prefix)
+			}
+			other, _ := newBackup(testBackupPolicy(tt.config), scheme.Scheme, start)
+			if other.dir == b.dir {
+				t.Errorf("runs started in the same second share directory %s", b.dir)
+			}
+		})
+	}
+}
+
+func TestBackupWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      client.Object
+		wantPath string
+	}{
+		{
+			name: "namespaced",
+			obj: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:          "settings",
+					Namespace:     "apps",
+					ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+				},
+				Data: map[string]string{"key": "value"},
+			},
+			wantPath: "apps/ConfigMap/settings.yaml",
+		},
+		{
+			name:     "with status",
+			obj:      &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "apps"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+			wantPath: "apps/PersistentVolumeClaim/data.yaml",
+		},
+		{
+			name:     "cluster-scoped",
+			obj:      &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "reader"}},
+			wantPath: "_cluster/ClusterRole/reader.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &backup{dir: t.TempDir(), scheme: scheme.Scheme}
+			path, err := b.write(tt.obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := filepath.Join(b.dir, tt.wantPath); path != want {
+				t.Errorf("got path %s, want %s", path, want)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("got mode %v, want 0600", info.Mode().Perm())
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var content map[string]interface{}
+			if err := yaml.Unmarshal(data, &content); err != nil {
+				t.Fatal(err)
+			}
+			if content["kind"] == nil || content["apiVersion"] == nil {
+				t.Errorf("backup has no type information:\n%s", data)
+			}
+			if _, exists := content["status"]; exists {
+				t.Errorf("backup contains the status:\n%s", data)
+			}
+			if metadata, _ := content["metadata"].(map[string]interface{}); metadata["managedFields"] != nil {
+				t.Errorf("backup contains managed fields:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestSweepBackups(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		retentionDays int32
+		dirs          []string
+		wantRemaining []string
+	}{
+		{
+			name:          "runs older than the retention removed",
+			retentionDays: 7,
+			dirs:          []string{"20240601-020000-abcde", "20240603-115959-abcde", "20240603-120001-abcde", "20240610-020000-abcde"},
+			wantRemaining: []string{"20240603-120001-abcde", "20240610-020000-abcde"},
+		},
+		{
+			name:          "runs without suffix",
+			retentionDays: 7,
+			dirs:          []string{"20240601-020000", "20240609-020000"},
+			wantRemaining: []string{"20240609-020000"},
+		},
+		{
+			name:          "other directories kept",
+			retentionDays: 7,
+			dirs:          []string{"manual", "20240601-020000.old", "2024"},
+			wantRemaining: []string{"2024", "20240601-020000.old", "manual"},
+		},
+		{
+			name:          "no retention",
+			dirs:          []string{"20200101-000000-abcde"},
+			wantRemaining: []string{"20200101-000000-abcde"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testBackupPolicy(&opsv1alpha1.BackupConfig{Enabled: true, Type: BackupTypeLocal, Location: t.TempDir(), RetentionDays: tt.retentionDays})
+
+			// A policy of the same name in another namespace keeps its runs
+			other := testBackupPolicy(policy.Spec.BackupConfig)
+			other.Namespace = "team-b"
+			for _, dir := range tt.dirs {
+				for _, p := range []*opsv1alpha1.JanitorPolicy{policy, other} {
+					if err := os.MkdirAll(filepath.Join(policyBackupDir(p), dir), 0o700); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			removed, err := sweepBackups(policy, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := len(tt.dirs) - len(tt.wantRemaining); removed != want {
+				t.Errorf("removed %d runs, want %d", removed, want)
+			}
+			if got := listDirs(t, policyBackupDir(policy)); !reflect.DeepEqual(got, tt.wantRemaining) {
+				t.Errorf("remaining %v, want %v", got, tt.wantRemaining)
+			}
+			if got := listDirs(t, policyBackupDir(other)); len(got) != len(tt.dirs) {
+				t.Errorf("runs of the other policy changed to %v", got)
+			}
+		})
+	}
+}
+
+// listDirs returns the sorted names of the entries of the directory
+func listDirs(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
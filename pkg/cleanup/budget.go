@@ -0,0 +1,97 @@
+package cleanup
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// checkBudget returns a description of the first deletion budget limit exceeded by the plan,
+// or an empty string if the plan stays within the budget. The limits of the whole policy are
+// checked first, then those of the individual cleaners in name order.
+func checkBudget(budget *opsv1alpha1.DeletionBudget, candidates []Candidate, statsByCleaner map[string]*opsv1alpha1.ResourceTypeStats) string {
+	if budget == nil {
+		return ""
+	}
+
+	var scanned int32
+	for _, stats := range statsByCleaner {
+		scanned += stats.Scanned
+	}
+	if exceeded := exceedsLimits(budget.BudgetLimits, candidates, scanned); exceeded != "" {
+		return exceeded
+	}
+
+	names := make([]string, 0, len(budget.Cleaners))
+	for name := range budget.Cleaners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		stats, planned := statsByCleaner[name]
+		if !planned {
+			continue
+		}
+
+		var cleanerCandidates []Candidate
+		for i := range candidates {
+			if candidates[i].Cleaner == name {
+				cleanerCandidates = append(cleanerCandidates, candidates[i])
+			}
+		}
+		if exceeded := exceedsLimits(budget.Cleaners[name], cleanerCandidates, stats.Scanned); exceeded != "" {
+			return fmt.Sprintf("cleaner %s: %s", name, exceeded)
+		}
+	}
+
+	return ""
+}
+
+// exceedsLimits checks the candidates against one set of limits. Several candidates for the
+// same object count as one resource.
+func exceedsLimits(limits opsv1alpha1.BudgetLimits, candidates []Candidate, scanned int32) string {
+	seen := make(map[types.UID]bool)
+	perNamespace := make(map[string]int32)
+	var total int32
+	for i := range candidates {
+		obj := candidates[i].Object
+		if uid := obj.GetUID(); uid != "" {
+			if seen[uid] {
+				continue
+			}
+			seen[uid] = true
+		}
+		total++
+		if obj.GetNamespace() != "" {
+			perNamespace[obj.GetNamespace()]++
+		}
+	}
+
+	if limits.MaxDeletions != nil && total > *limits.MaxDeletions {
+		return fmt.Sprintf("plan changes %d resources, above maxDeletions=%d", total, *limits.MaxDeletions)
+	}
+
+	if limits.MaxPercent != nil && scanned > 0 && int64(total)*100 > int64(*limits.MaxPercent)*int64(scanned) {
+		return fmt.Sprintf("plan changes %d of %d scanned resources, above maxPercent=%d", total, scanned, *limits.MaxPercent)
+	}
+
+	if limits.MaxPerNamespace != nil {
+		namespaces := make([]string, 0, len(perNamespace))
+		for namespace := range perNamespace {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+
+		for _, namespace := range namespaces {
+			if count := perNamespace[namespace]; count > *limits.MaxPerNamespace {
+				return fmt.Sprintf("plan changes %d resources in namespace %s, above maxPerNamespace=%d", count, namespace, *limits.MaxPerNamespace)
+			}
+		}
+	}
+
+	return ""
+}
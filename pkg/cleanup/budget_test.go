@@ -0,0 +1,127 @@
+package cleanup
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testCandidate returns a delete candidate of the cleaner for a ConfigMap
+func testCandidate(cleaner, namespace, name string) Candidate {
+	return Candidate{
+		Cleaner: cleaner,
+		Object:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID(namespace + "/" + name)}},
+		Kind:    "ConfigMap",
+		Action:  ActionDelete,
+	}
+}
+
+func TestExceedsLimits(t *testing.T) {
+	threeInTwoNamespaces := []Candidate{
+		testCandidate("jobs", "a", "1"),
+		testCandidate("jobs", "a", "2"),
+		testCandidate("jobs", "b", "1"),
+	}
+
+	tests := []struct {
+		name       string
+		limits     opsv1alpha1.BudgetLimits
+		candidates []Candidate
+		scanned    int32
+		want       string
+	}{
+		{name: "no limits", candidates: threeInTwoNamespaces, scanned: 3},
+		{name: "at maxDeletions", limits: opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](3)}, candidates: threeInTwoNamespaces, scanned: 10},
+		{name: "above maxDeletions", limits: opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](2)}, candidates: threeInTwoNamespaces, scanned: 10, want: "maxDeletions=2"},
+		{name: "maxDeletions of zero", limits: opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](0)}, candidates: threeInTwoNamespaces[:1], scanned: 10, want: "maxDeletions=0"},
+		{name: "empty plan", limits: opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](0)}, scanned: 10},
+		{
+			name:       "candidates for the same object count once",
+			limits:     opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](1)},
+			candidates: []Candidate{testCandidate("jobs", "a", "1"), testCandidate("jobs", "a", "1")},
+			scanned:    10,
+		},
+		{name: "at maxPercent", limits: opsv1alpha1.BudgetLimits{MaxPercent: ptr.To[int32](30)}, candidates: threeInTwoNamespaces, scanned: 10},
+		{name: "above maxPercent", limits: opsv1alpha1.BudgetLimits{MaxPercent: ptr.To[int32](29)}, candidates: threeInTwoNamespaces, scanned: 10, want: "maxPercent=29"},
+		{name: "maxPercent without scanned resources", limits: opsv1alpha1.BudgetLimits{MaxPercent: ptr.To[int32](0)}, candidates: threeInTwoNamespaces},
+		{name: "at maxPerNamespace", limits: opsv1alpha1.BudgetLimits{MaxPerNamespace: ptr.To[int32](2)}, candidates: threeInTwoNamespaces, scanned: 10},
+		{name: "above maxPerNamespace", limits: opsv1alpha1.BudgetLimits{MaxPerNamespace: ptr.To[int32](1)}, candidates: threeInTwoNamespaces, scanned: 10, want: "namespace a, above maxPerNamespace=1"},
+		{
+			name:       "cluster-scoped resources are not counted per namespace",
+			limits:     opsv1alpha1.BudgetLimits{MaxPerNamespace: ptr.To[int32](0)},
+			candidates: []Candidate{testCandidate("rbac", "", "role")},
+			scanned:    10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exceedsLimits(tt.limits, tt.candidates, tt.scanned)
+			switch {
+			case tt.want == "" && got != "":
+				t.Errorf("unexpected budget violation %q", got)
+			case tt.want != "" && !strings.Contains(got, tt.want):
+				t.Errorf("got %q, want a violation of %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	candidates := []Candidate{
+		testCandidate("jobs", "a", "1"),
+		testCandidate("jobs", "a", "2"),
+		testCandidate("pvc", "a", "3"),
+	}
+	statsByCleaner := map[string]*opsv1alpha1.ResourceTypeStats{
+		"jobs": {Scanned: 4},
+		"pvc":  {Scanned: 2},
+	}
+
+	tests := []struct {
+		name   string
+		budget *opsv1alpha1.DeletionBudget
+		want   string
+	}{
+		{name: "no budget"},
+		{name: "within policy limits", budget: &opsv1alpha1.DeletionBudget{BudgetLimits: opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](3), MaxPercent: ptr.To[int32](50)}}},
+		{name: "above policy limits", budget: &opsv1alpha1.DeletionBudget{BudgetLimits: opsv1alpha1.BudgetLimits{MaxPercent: ptr.To[int32](49)}}, want: "3 of 6 scanned resources"},
+		{
+			name: "above cleaner limits",
+			budget: &opsv1alpha1.DeletionBudget{
+				BudgetLimits: opsv1alpha1.BudgetLimits{MaxDeletions: ptr.To[int32](10)},
+				Cleaners:     map[string]opsv1alpha1.BudgetLimits{"jobs": {MaxDeletions: ptr.To[int32](5)}, "pvc": {MaxPercent: ptr.To[int32](40)}},
+			},
+			want: "cleaner pvc: plan changes 1 of 2 scanned resources",
+		},
+		{
+			name: "cleaners checked in name order",
+			budget: &opsv1alpha1.DeletionBudget{
+				Cleaners: map[string]opsv1alpha1.BudgetLimits{"pvc": {MaxDeletions: ptr.To[int32](0)}, "jobs": {MaxDeletions: ptr.To[int32](1)}},
+			},
+			want: "cleaner jobs:",
+		},
+		{
+			name:   "cleaner without plan",
+			budget: &opsv1alpha1.DeletionBudget{Cleaners: map[string]opsv1alpha1.BudgetLimits{"secrets": {MaxDeletions: ptr.To[int32](0)}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkBudget(tt.budget, candidates, statsByCleaner)
+			switch {
+			case tt.want == "" && got != "":
+				t.Errorf("unexpected budget violation %q", got)
+			case tt.want != "" && !strings.Contains(got, tt.want):
+				t.Errorf("got %q, want a violation of %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,154 +0,0 @@
-package cleanup
-
-import (
-	"context"
-
-	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
-)
-
-// Stub implementations for cleaners that are not yet fully implemented
-// These provide the interface structure and can be expanded later
-
-// ConfigMapsCleaner handles cleanup of unused ConfigMaps
-type ConfigMapsCleaner struct{}
-
-func NewConfigMapsCleaner() *ConfigMapsCleaner {
-	return &ConfigMapsCleaner{}
-}
-
-func (c *ConfigMapsCleaner) Name() string {
-	return "configmaps"
-}
-
-func (c *ConfigMapsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement ConfigMaps cleanup logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// SecretsCleaner handles cleanup of unused Secrets
-type SecretsCleaner struct{}
-
-func NewSecretsCleaner() *SecretsCleaner {
-	return &SecretsCleaner{}
-}
-
-func (c *SecretsCleaner) Name() string {
-	return "secrets"
-}
-
-func (c *SecretsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement Secrets cleanup logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// ServicesCleaner handles cleanup of orphaned Services
-type ServicesCleaner struct{}
-
-func NewServicesCleaner() *ServicesCleaner {
-	return &ServicesCleaner{}
-}
-
-func (c *ServicesCleaner) Name() string {
-	return "services"
-}
-
-func (c *ServicesCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement Services cleanup logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// TLSSecretsCleaner handles cleanup of expired TLS certificates
-type TLSSecretsCleaner struct{}
-
-func NewTLSSecretsCleaner() *TLSSecretsCleaner {
-	return &TLSSecretsCleaner{}
-}
-
-func (c *TLSSecretsCleaner) Name() string {
-	return "tlssecrets"
-}
-
-func (c *TLSSecretsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement TLS Secrets cleanup logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// TerminatingPodsCleaner handles cleanup of stuck terminating Pods
-type TerminatingPodsCleaner struct{}
-
-func NewTerminatingPodsCleaner() *TerminatingPodsCleaner {
-	return &TerminatingPodsCleaner{}
-}
-
-func (c *TerminatingPodsCleaner) Name() string {
-	return "terminatingpods"
-}
-
-func (c *TerminatingPodsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement terminating Pods cleanup logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// CrashLoopPodsCleaner handles detection and action on crash looping Pods
-type CrashLoopPodsCleaner struct{}
-
-func NewCrashLoopPodsCleaner() *CrashLoopPodsCleaner {
-	return &CrashLoopPodsCleaner{}
-}
-
-func (c *CrashLoopPodsCleaner) Name() string {
-	return "crashlooppods"
-}
-
-func (c *CrashLoopPodsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement crash loop Pods detection and handling logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// ResourceGapsChecker detects Pods without resource limits/requests
-type ResourceGapsChecker struct{}
-
-func NewResourceGapsChecker() *ResourceGapsChecker {
-	return &ResourceGapsChecker{}
-}
-
-func (c *ResourceGapsChecker) Name() string {
-	return "resourcegaps"
-}
-
-func (c *ResourceGapsChecker) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement resource gaps detection logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// RBACChecker validates RBAC configurations
-type RBACChecker struct{}
-
-func NewRBACChecker() *RBACChecker {
-	return &RBACChecker{}
-}
-
-func (c *RBACChecker) Name() string {
-	return "rbaccheck"
-}
-
-func (c *RBACChecker) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement RBAC validation logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
-
-// StaleHelmReleasesCleaner handles cleanup of failed/orphaned Helm releases
-type StaleHelmReleasesCleaner struct{}
-
-func NewStaleHelmReleasesCleaner() *StaleHelmReleasesCleaner {
-	return &StaleHelmReleasesCleaner{}
-}
-
-func (c *StaleHelmReleasesCleaner) Name() string {
-	return "stalehelm"
-}
-
-func (c *StaleHelmReleasesCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
-	// TODO: Implement stale Helm releases cleanup logic
-	return &opsv1alpha1.ResourceTypeStats{}, nil
-}
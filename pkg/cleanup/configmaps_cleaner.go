@@ -0,0 +1,182 @@
+package cleanup
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// rootCAConfigMapName is the ConfigMap published into every namespace by kube-controller-manager
+	rootCAConfigMapName = "kube-root-ca.crt"
+
+	// leaderElectionAnnotation marks ConfigMaps used as leader election locks
+	leaderElectionAnnotation = "control-plane.alpha.kubernetes.io/leader"
+)
+
+// ConfigMapsCleaner handles cleanup of unused ConfigMaps
+type ConfigMapsCleaner struct{}
+
+// NewConfigMapsCleaner creates a new ConfigMaps cleaner
+func NewConfigMapsCleaner() *ConfigMapsCleaner {
+	return &ConfigMapsCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *ConfigMapsCleaner) Name() string {
+	return "configmaps"
+}
+
+// Enabled reports whether the policy enables ConfigMaps cleanup
+func (c *ConfigMapsCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.ConfigMaps != nil && policy.Spec.Cleanup.ConfigMaps.Enabled
+}
+
+// Plan finds unused ConfigMaps to delete
+func (c *ConfigMapsCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("configmaps-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.ConfigMaps
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	// Parse duration
+	olderThan, err := time.ParseDuration(config.OlderThan)
+	if err != nil {
+		log.Error(err, "Failed to parse olderThan duration", "duration", config.OlderThan)
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	cutoffTime := time.Now().Add(-olderThan)
+
+	// Get all ConfigMaps
+	var configMapList corev1.ConfigMapList
+	if err := cleanupCtx.Client.List(ctx, &configMapList); err != nil {
+		log.Error(err, "Failed to list ConfigMaps")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	stats.Scanned = int32(len(configMapList.Items))
+
+	// Build map of referenced ConfigMaps
+	referenced := make(map[string]bool)
+	if config.CheckReferences {
+		specs, err := listPodSpecs(ctx, cleanupCtx.Client)
+		if err != nil {
+			log.Error(err, "Failed to build ConfigMap reference graph")
+			stats.Errors++
+			return nil, stats, err
+		}
+		referenced = configMapReferences(specs)
+	}
+
+	reason := "older than " + config.OlderThan
+	if config.CheckReferences {
+		reason = "not referenced by any workload"
+	}
+
+	// Process each ConfigMap
+	var candidates []Candidate
+	for i := range configMapList.Items {
+		cm := &configMapList.Items[i]
+		if c.shouldSkipConfigMap(cm, cleanupCtx) {
+			log.V(1).Info("Skipping ConfigMap", "name", cm.Name, "namespace", cm.Namespace)
+			stats.Skipped++
+			continue
+		}
+
+		// Check if ConfigMap is referenced
+		if referenced[cm.Namespace+"/"+cm.Name] {
+			log.V(1).Info("ConfigMap is referenced, skipping", "name", cm.Name, "namespace", cm.Namespace)
+			stats.Skipped++
+			continue
+		}
+
+		// Check if ConfigMap is old enough
+		if cm.CreationTimestamp.Time.After(cutoffTime) {
+			log.V(1).Info("ConfigMap is too new, skipping", "name", cm.Name, "namespace", cm.Namespace, "age", time.Since(cm.CreationTimestamp.Time))
+			stats.Skipped++
+			continue
+		}
+
+		// ConfigMap is unreferenced and old enough to be cleaned
+		candidates = append(candidates, Candidate{
+			Object:      cm,
+			Kind:        "ConfigMap",
+			Description: "unused ConfigMap",
+			Action:      ActionDelete,
+			Reason:      reason,
+			Age:         time.Since(cm.CreationTimestamp.Time),
+			Rule:        "olderThan=" + config.OlderThan,
+		})
+	}
+
+	log.Info("ConfigMaps planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// shouldSkipConfigMap determines if a ConfigMap should be skipped
+func (c *ConfigMapsCleaner) shouldSkipConfigMap(cm *corev1.ConfigMap, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if IsNamespaceIgnored(cm.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if ConfigMap has protected labels
+	if IsProtected(cm.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	// Skip if ConfigMap is in terminating state
+	if cm.DeletionTimestamp != nil {
+		return true
+	}
+
+	// Owned ConfigMaps are garbage collected together with their owner
+	if len(cm.OwnerReferences) > 0 {
+		return true
+	}
+
+	// Skip system ConfigMaps
+	if c.isSystemConfigMap(cm) {
+		return true
+	}
+
+	return false
+}
+
+// isSystemConfigMap checks if a ConfigMap is a system ConfigMap that should not be deleted
+func (c *ConfigMapsCleaner) isSystemConfigMap(cm *corev1.ConfigMap) bool {
+	if cm.Name == rootCAConfigMapName {
+		return true
+	}
+
+	if _, exists := cm.Annotations[leaderElectionAnnotation]; exists {
+		return true
+	}
+
+	// Check for system labels
+	if cm.Labels != nil {
+		if component := cm.Labels["k8s-app"]; component != "" {
+			return true
+		}
+		// Helm release storage when using the configmap driver
+		if owner := cm.Labels["owner"]; owner == "helm" {
+			return true
+		}
+	}
+
+	return false
+}
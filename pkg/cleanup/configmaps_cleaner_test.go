@@ -0,0 +1,119 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestShouldSkipConfigMap(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name      string
+		configMap corev1.ConfigMap
+		want      bool
+	}{
+		{name: "plain", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings"}}},
+		{name: "root CA", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: rootCAConfigMapName}}, want: true},
+		{name: "owned", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:            "settings",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}},
+		}}, want: true},
+		{name: "leader election lock", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:        "controller-lock",
+			Annotations: map[string]string{leaderElectionAnnotation: "{}"},
+		}}, want: true},
+		{name: "Helm release storage", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:   "sh.helm.release.v1.web.v1",
+			Labels: map[string]string{"owner": "helm"},
+		}}, want: true},
+		{name: "system component", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:   "coredns",
+			Labels: map[string]string{"k8s-app": "kube-dns"},
+		}}, want: true},
+		{name: "ignored namespace", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "kube-system"}}, want: true},
+		{name: "protected label", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:   "settings",
+			Labels: map[string]string{"janitor.k8s.io/keep": "true"},
+		}}, want: true},
+		{name: "terminating", configMap: corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", DeletionTimestamp: &now}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.IgnoreNamespaces = []string{"kube-system"}
+			policy.Spec.ProtectedLabels = []string{"janitor.k8s.io/keep"}
+
+			configMap := tt.configMap
+			if configMap.Namespace == "" {
+				configMap.Namespace = "apps"
+			}
+			if got := NewConfigMapsCleaner().shouldSkipConfigMap(&configMap, newTestContext(policy)); got != tt.want {
+				t.Errorf("got skip %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigMapsPlan(t *testing.T) {
+	configMap := func(name string, age time.Duration) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "apps",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		}}
+	}
+	objects := []client.Object{
+		configMap("unused", 48*time.Hour),
+		configMap("recent", time.Hour),
+		configMap("mounted", 48*time.Hour),
+		configMap(rootCAConfigMapName, 48*time.Hour),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name:         "config",
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "mounted"}}},
+			}}},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		checkReferences bool
+		want            []string
+	}{
+		{name: "unreferenced", checkReferences: true, want: []string{"unused"}},
+		{name: "by age only", want: []string{"mounted", "unused"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Cleanup.ConfigMaps = &opsv1alpha1.ConfigMapsCleanupConfig{Enabled: true, OlderThan: "24h", CheckReferences: tt.checkReferences}
+			cleanupCtx := newTestContext(policy, objects...)
+
+			candidates, _, err := NewConfigMapsCleaner().Plan(context.Background(), cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, candidate := range candidates {
+				names = append(names, candidate.Object.GetName())
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("planned %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// CrashLoopActionRestart deletes the pod so that its controller recreates it
+	CrashLoopActionRestart = "restart"
+
+	// CrashLoopActionAlert only emits events and notifications
+	CrashLoopActionAlert = "alert"
+
+	// CrashLoopActionDelete scales the owning workload to zero
+	CrashLoopActionDelete = "delete"
+
+	// defaultRestartThreshold is used when CrashLoopPodsConfig.RestartThreshold is not set
+	defaultRestartThreshold = 5
+
+	// scaledDownFromAnnotation records the replica count of a workload before it was scaled to zero
+	scaledDownFromAnnotation = "janitor.io/scaled-down-from"
+)
+
+// crashLoopContainer describes a container stuck in CrashLoopBackOff
+type crashLoopContainer struct {
+	Name         string
+	RestartCount int32
+	LastReason   string
+	LastExitCode int32
+}
+
+// CrashLoopPodsCleaner handles detection and action on crash looping Pods
+type CrashLoopPodsCleaner struct{}
+
+// NewCrashLoopPodsCleaner creates a new crash loop Pods cleaner
+func NewCrashLoopPodsCleaner() *CrashLoopPodsCleaner {
+	return &CrashLoopPodsCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *CrashLoopPodsCleaner) Name() string {
+	return "crashlooppods"
+}
+
+// Enabled reports whether the policy enables crash loop pods handling
+func (c *CrashLoopPodsCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.CrashLoopPods != nil && policy.Spec.Cleanup.CrashLoopPods.Enabled
+}
+
+// Plan detects crash looping Pods and plans the configured action
+func (c *CrashLoopPodsCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("crashlooppods-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.CrashLoopPods
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	threshold := config.RestartThreshold
+	if threshold <= 0 {
+		threshold = defaultRestartThreshold
+	}
+
+	action := config.Action
+	if action == "" {
+		action = CrashLoopActionAlert
+	}
+
+	// Get all pods
+	var podList corev1.PodList
+	if err := cleanupCtx.Client.List(ctx, &podList); err != nil {
+		log.Error(err, "Failed to list pods")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	stats.Scanned = int32(len(podList.Items))
+
+	var alerts []string
+	scaled := make(map[string]bool)
+	rule := fmt.Sprintf("restartThreshold=%d,action=%s", threshold, action)
+
+	// Process each pod
+	var candidates []Candidate
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if c.shouldSkipPod(pod, cleanupCtx) {
+			stats.Skipped++
+			continue
+		}
+
+		containers := c.crashLoopingContainers(pod, threshold)
+		if len(containers) == 0 {
+			continue
+		}
+
+		summary := describeCrashLoop(containers)
+		owner := c.resolveOwner(ctx, cleanupCtx, pod)
+
+		log.Info("Pod is crash looping", "name", pod.Name, "namespace", pod.Namespace, "owner", owner, "containers", summary)
+		cleanupCtx.EventRecorder.Event(pod, "Warning", "CrashLoopDetected", "Pod is crash looping: "+summary)
+		cleanupCtx.Report(opsv1alpha1.Finding{
+			Cleaner:   c.Name(),
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Reason:    "CrashLoopBackOff",
+			Message:   summary,
+		})
+
+		switch {
+		case action == CrashLoopActionAlert:
+			alerts = append(alerts, fmt.Sprintf("%s/%s: %s", pod.Namespace, pod.Name, summary))
+			stats.Skipped++
+
+		case owner.Kind == "":
+			// Bare pods have no controller to recreate or scale them
+			if action == CrashLoopActionRestart {
+				log.Info("Bare pod cannot be restarted, alerting instead", "name", pod.Name, "namespace", pod.Namespace)
+				alerts = append(alerts, fmt.Sprintf("%s/%s (bare pod, not restarted): %s", pod.Namespace, pod.Name, summary))
+				stats.Skipped++
+				continue
+			}
+			candidates = append(candidates, c.podCandidate(pod, "bare pod is crash looping: "+summary, rule))
+
+		case action == CrashLoopActionRestart:
+			candidates = append(candidates, c.podCandidate(pod, fmt.Sprintf("restarting crash looping pod of %s %s: %s", owner.Kind, owner.Name, summary), rule))
+
+		case action == CrashLoopActionDelete:
+			if owner.Kind != "Deployment" && owner.Kind != "StatefulSet" {
+				log.Info("Owner cannot be scaled, alerting instead", "name", pod.Name, "namespace", pod.Namespace, "owner", owner)
+				alerts = append(alerts, fmt.Sprintf("%s/%s (owned by %s %s, not scaled): %s", pod.Namespace, pod.Name, owner.Kind, owner.Name, summary))
+				stats.Skipped++
+				continue
+			}
+			ownerKey := owner.Kind + "/" + pod.Namespace + "/" + owner.Name
+			if scaled[ownerKey] {
+				stats.Skipped++
+				continue
+			}
+			scaled[ownerKey] = true
+			candidate, err := c.planScaleToZero(ctx, cleanupCtx, pod.Namespace, owner, rule)
+			if err != nil {
+				log.Error(err, "Failed to plan scaling owner to zero", "namespace", pod.Namespace, "owner", owner)
+				stats.Errors++
+				continue
+			}
+			if candidate == nil {
+				stats.Skipped++
+				continue
+			}
+			candidates = append(candidates, *candidate)
+		}
+	}
+
+	// The engine sends the alerts with the notification for the complete plan
+	for _, alert := range alerts {
+		cleanupCtx.Alert("crash looping pod " + alert)
+	}
+
+	log.Info("Crash loop pods planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// shouldSkipPod determines if a pod should be skipped
+func (c *CrashLoopPodsCleaner) shouldSkipPod(pod *corev1.Pod, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if IsNamespaceIgnored(pod.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if pod has protected labels
+	if IsProtected(pod.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	// Skip if pod is in terminating state
+	if pod.DeletionTimestamp != nil {
+		return true
+	}
+
+	return false
+}
+
+// crashLoopingContainers returns the containers in CrashLoopBackOff that restarted more than the threshold
+func (c *CrashLoopPodsCleaner) crashLoopingContainers(pod *corev1.Pod, threshold int32) []crashLoopContainer {
+	var result []crashLoopContainer
+
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if status.State.Waiting == nil || status.State.Waiting.Reason != "CrashLoopBackOff" {
+			continue
+		}
+		if status.RestartCount <= threshold {
+			continue
+		}
+
+		container := crashLoopContainer{
+			Name:         status.Name,
+			RestartCount: status.RestartCount,
+		}
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			container.LastReason = terminated.Reason
+			container.LastExitCode = terminated.ExitCode
+		}
+		result = append(result, container)
+	}
+
+	return result
+}
+
+// describeCrashLoop renders a one-line summary of crash looping containers
+func describeCrashLoop(containers []crashLoopContainer) string {
+	parts := make([]string, 0, len(containers))
+	for _, container := range containers {
+		reason := container.LastReason
+		if reason == "" {
+			reason = "Unknown"
+		}
+		parts = append(parts, fmt.Sprintf("container %s restarted %d times, last termination %s (exit code %d)",
+			container.Name, container.RestartCount, reason, container.LastExitCode))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// resolveOwner returns the top-level controller of a pod, following ReplicaSets up to their Deployment.
+// A zero value is returned for bare pods.
+func (c *CrashLoopPodsCleaner) resolveOwner(ctx context.Context, cleanupCtx *Context, pod *corev1.Pod) metav1.OwnerReference {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return metav1.OwnerReference{}
+	}
+
+	if ref.Kind == "ReplicaSet" {
+		var rs appsv1.ReplicaSet
+		if err := cleanupCtx.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, &rs); err == nil {
+			if rsOwner := metav1.GetControllerOf(&rs); rsOwner != nil {
+				return *rsOwner
+			}
+		}
+	}
+
+	return *ref
+}
+
+// podCandidate plans the deletion of a crash looping pod
+func (c *CrashLoopPodsCleaner) podCandidate(pod *corev1.Pod, reason, rule string) Candidate {
+	return Candidate{
+		Object:      pod,
+		Kind:        "Pod",
+		Description: "crash looping pod",
+		Action:      ActionDelete,
+		Reason:      reason,
+		Age:         time.Since(pod.CreationTimestamp.Time),
+		Rule:        rule,
+	}
+}
+
+// planScaleToZero plans scaling the owning Deployment or StatefulSet to zero replicas. The previous
+// replica count is kept in an annotation so the workload can be restored. It returns nil if the
+// owner is protected or already scaled down.
+func (c *CrashLoopPodsCleaner) planScaleToZero(ctx context.Context, cleanupCtx *Context, namespace string, owner metav1.OwnerReference, rule string) (*Candidate, error) {
+	log := cleanupCtx.Logger.WithName("crashlooppods-cleaner")
+	key := types.NamespacedName{Namespace: namespace, Name: owner.Name}
+
+	var obj client.Object
+	var replicas **int32
+	switch owner.Kind {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		obj, replicas = deployment, &deployment.Spec.Replicas
+	case "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		obj, replicas = statefulSet, &statefulSet.Spec.Replicas
+	default:
+		return nil, fmt.Errorf("unsupported owner kind %s", owner.Kind)
+	}
+
+	if err := cleanupCtx.Client.Get(ctx, key, obj); err != nil {
+		return nil, err
+	}
+
+	if IsProtected(obj.GetLabels(), cleanupCtx.Policy.Spec.ProtectedLabels) {
+		log.Info("Owner is protected, not scaling", "kind", owner.Kind, "name", owner.Name, "namespace", namespace)
+		return nil, nil
+	}
+
+	previous := int32(1)
+	if *replicas != nil {
+		previous = **replicas
+	}
+	if previous == 0 {
+		return nil, nil
+	}
+
+	return &Candidate{
+		Object:      obj,
+		Kind:        owner.Kind,
+		Description: "crash looping workload",
+		Action:      ActionPatch,
+		Reason:      fmt.Sprintf("scaling to zero from %d replicas because its pods are crash looping", previous),
+		Age:         time.Since(obj.GetCreationTimestamp().Time),
+		Rule:        rule,
+		Mutate: func() {
+			zero := int32(0)
+			*replicas = &zero
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[scaledDownFromAnnotation] = strconv.Itoa(int(previous))
+			obj.SetAnnotations(annotations)
+		},
+	}, nil
+}
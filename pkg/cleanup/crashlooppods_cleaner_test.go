@@ -0,0 +1,266 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// crashLoopStatus returns the status of a container waiting in CrashLoopBackOff
+func crashLoopStatus(name string, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:         name,
+		RestartCount: restarts,
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1},
+		},
+	}
+}
+
+// newCrashLoopPod returns a pod with a container crash looping 10 times, controlled by owner if given
+func newCrashLoopPod(name string, owner *metav1.OwnerReference) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{crashLoopStatus("app", 10)}},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+// controllerRef returns a controller owner reference
+func controllerRef(kind, name string) *metav1.OwnerReference {
+	return &metav1.OwnerReference{Kind: kind, Name: name, Controller: ptr.To(true)}
+}
+
+func TestCrashLoopingContainers(t *testing.T) {
+	tests := []struct {
+		name   string
+		status corev1.PodStatus
+		want   []crashLoopContainer
+	}{
+		{
+			name:   "above the threshold",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{crashLoopStatus("app", 6)}},
+			want:   []crashLoopContainer{{Name: "app", RestartCount: 6, LastReason: "Error", LastExitCode: 1}},
+		},
+		{
+			name:   "at the threshold",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{crashLoopStatus("app", 5)}},
+		},
+		{
+			name:   "init container",
+			status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{crashLoopStatus("migrate", 7)}},
+			want:   []crashLoopContainer{{Name: "migrate", RestartCount: 7, LastReason: "Error", LastExitCode: 1}},
+		},
+		{
+			name: "without a last termination",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 8,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}},
+			want: []crashLoopContainer{{Name: "app", RestartCount: 8}},
+		},
+		{
+			name: "waiting for another reason",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 8,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}}},
+		},
+		{
+			name: "running again",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 8,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: tt.status}
+			if got := NewCrashLoopPodsCleaner().crashLoopingContainers(pod, 5); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrashLoopPodsPlan(t *testing.T) {
+	deployment := func(replicas *int32, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps", Labels: labels},
+			Spec:       appsv1.DeploymentSpec{Replicas: replicas},
+		}
+	}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-5d4f",
+		Namespace:       "apps",
+		OwnerReferences: []metav1.OwnerReference{*controllerRef("Deployment", "web")},
+	}}
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"}}
+
+	tests := []struct {
+		name    string
+		action  string
+		objects []client.Object
+		// wantPlanned lists the planned changes as "action kind/name"
+		wantPlanned []string
+		wantAlerts  int
+		// wantScaledFrom maps the workloads scaled down by applying the plan to their annotation
+		wantScaledFrom map[string]string
+	}{
+		{
+			name:   "alert",
+			action: CrashLoopActionAlert,
+			objects: []client.Object{
+				deployment(ptr.To[int32](3), nil), replicaSet,
+				newCrashLoopPod("web-5d4f-a", controllerRef("ReplicaSet", "web-5d4f")),
+			},
+			wantAlerts: 1,
+		},
+		{
+			name:   "restart a pod of a Deployment",
+			action: CrashLoopActionRestart,
+			objects: []client.Object{
+				deployment(ptr.To[int32](3), nil), replicaSet,
+				newCrashLoopPod("web-5d4f-a", controllerRef("ReplicaSet", "web-5d4f")),
+			},
+			wantPlanned: []string{"Delete Pod/web-5d4f-a"},
+		},
+		{
+			name:   "scale a Deployment to zero once for all its pods",
+			action: CrashLoopActionDelete,
+			objects: []client.Object{
+				deployment(ptr.To[int32](3), nil), replicaSet,
+				newCrashLoopPod("web-5d4f-a", controllerRef("ReplicaSet", "web-5d4f")),
+				newCrashLoopPod("web-5d4f-b", controllerRef("ReplicaSet", "web-5d4f")),
+			},
+			wantPlanned:    []string{"Patch Deployment/web"},
+			wantScaledFrom: map[string]string{"Deployment/web": "3"},
+		},
+		{
+			name:   "scale a StatefulSet with the default replica count to zero",
+			action: CrashLoopActionDelete,
+			objects: []client.Object{
+				statefulSet,
+				newCrashLoopPod("db-0", controllerRef("StatefulSet", "db")),
+			},
+			wantPlanned:    []string{"Patch StatefulSet/db"},
+			wantScaledFrom: map[string]string{"StatefulSet/db": "1"},
+		},
+		{
+			name:   "Deployment already scaled to zero",
+			action: CrashLoopActionDelete,
+			objects: []client.Object{
+				deployment(ptr.To[int32](0), nil), replicaSet,
+				newCrashLoopPod("web-5d4f-a", controllerRef("ReplicaSet", "web-5d4f")),
+			},
+		},
+		{
+			name:   "protected Deployment",
+			action: CrashLoopActionDelete,
+			objects: []client.Object{
+				deployment(ptr.To[int32](3), map[string]string{"janitor.k8s.io/keep": "true"}), replicaSet,
+				newCrashLoopPod("web-5d4f-a", controllerRef("ReplicaSet", "web-5d4f")),
+			},
+		},
+		{
+			name:   "owner that cannot be scaled",
+			action: CrashLoopActionDelete,
+			objects: []client.Object{
+				newCrashLoopPod("agent-x", controllerRef("DaemonSet", "agent")),
+			},
+			wantAlerts: 1,
+		},
+		{
+			name:        "delete a bare pod",
+			action:      CrashLoopActionDelete,
+			objects:     []client.Object{newCrashLoopPod("debug", nil)},
+			wantPlanned: []string{"Delete Pod/debug"},
+		},
+		{
+			name:       "restart a bare pod",
+			action:     CrashLoopActionRestart,
+			objects:    []client.Object{newCrashLoopPod("debug", nil)},
+			wantAlerts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.ProtectedLabels = []string{"janitor.k8s.io/keep"}
+			policy.Spec.Cleanup.CrashLoopPods = &opsv1alpha1.CrashLoopPodsConfig{Enabled: true, RestartThreshold: 5, Action: tt.action}
+			cleanupCtx := newTestContext(policy, tt.objects...)
+			ctx := context.Background()
+
+			cleaner := NewCrashLoopPodsCleaner()
+			candidates, _, err := cleaner.Plan(ctx, cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var planned []string
+			for i := range candidates {
+				candidates[i].Cleaner = cleaner.Name()
+				planned = append(planned, string(candidates[i].Action)+" "+candidates[i].Kind+"/"+candidates[i].Object.GetName())
+			}
+			sort.Strings(planned)
+			if !reflect.DeepEqual(planned, tt.wantPlanned) {
+				t.Errorf("planned %v, want %v", planned, tt.wantPlanned)
+			}
+			if len(cleanupCtx.alerts) != tt.wantAlerts {
+				t.Errorf("got alerts %v, want %d", cleanupCtx.alerts, tt.wantAlerts)
+			}
+
+			engine := &Engine{locks: cleanupCtx.locks, pool: NewWorkerPool(1, 0, 0)}
+			statsByCleaner := map[string]*opsv1alpha1.ResourceTypeStats{cleaner.Name(): {}}
+			if err := engine.apply(ctx, cleanupCtx, candidates, map[string]int{}, statsByCleaner); err != nil {
+				t.Fatalf("unexpected error applying the plan: %v", err)
+			}
+			if stats := statsByCleaner[cleaner.Name()]; stats.Cleaned != int32(len(candidates)) {
+				t.Errorf("applied %d of %d changes: %+v", stats.Cleaned, len(candidates), stats)
+			}
+
+			for workload, want := range tt.wantScaledFrom {
+				kind, name, _ := strings.Cut(workload, "/")
+				var obj client.Object
+				var replicas **int32
+				switch kind {
+				case "Deployment":
+					current := &appsv1.Deployment{}
+					obj, replicas = current, &current.Spec.Replicas
+				case "StatefulSet":
+					current := &appsv1.StatefulSet{}
+					obj, replicas = current, &current.Spec.Replicas
+				}
+				if err := cleanupCtx.Client.Get(ctx, client.ObjectKey{Namespace: "apps", Name: name}, obj); err != nil {
+					t.Fatal(err)
+				}
+				if *replicas == nil || **replicas != 0 {
+					t.Errorf("%s was not scaled to zero", workload)
+				}
+				if got := obj.GetAnnotations()[scaledDownFromAnnotation]; got != want {
+					t.Errorf("%s scaled down from %q, want %q", workload, got, want)
+				}
+			}
+		})
+	}
+}
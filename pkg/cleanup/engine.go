@@ -3,6 +3,8 @@ package cleanup
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -10,8 +12,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+	"github.com/automationpi/kubejanitor/pkg/notification"
 )
 
+// maxFindings caps the number of findings published in the policy status
+const maxFindings = 100
+
 // Context holds the cleanup execution context
 type Context struct {
 	Client        client.Client
@@ -19,54 +25,103 @@ type Context struct {
 	DryRun        bool
 	Logger        logr.Logger
 	EventRecorder record.EventRecorder
+	Notifier      *notification.Notifier
+
+	findingsMu sync.Mutex
+	findings   []opsv1alpha1.Finding
+	alerts     []string
+
+	locks   *ResourceLocks
+	configs map[string]interface{}
+	backup  *backup
 }
 
-// Engine handles the cleanup execution
-type Engine struct {
-	cleaners map[string]Cleaner
+// Report records a finding to be published in the policy status
+func (c *Context) Report(finding opsv1alpha1.Finding) {
+	c.findingsMu.Lock()
+	defer c.findingsMu.Unlock()
+
+	if len(c.findings) >= maxFindings {
+		c.Logger.V(1).Info("Finding limit reached, dropping finding", "kind", finding.Kind, "name", finding.Name, "reason", finding.Reason)
+		return
+	}
+	c.findings = append(c.findings, finding)
 }
 
-// Cleaner interface defines the cleanup behavior for specific resource types
-type Cleaner interface {
-	Name() string
-	Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error)
+// Alert queues a line for the notification the engine sends once the plan is complete.
+// Cleaners use it instead of notifying directly, so nothing is sent while planning.
+func (c *Context) Alert(text string) {
+	c.findingsMu.Lock()
+	defer c.findingsMu.Unlock()
+
+	c.alerts = append(c.alerts, text)
 }
 
-// NewEngine creates a new cleanup engine
-func NewEngine() *Engine {
-	engine := &Engine{
-		cleaners: make(map[string]Cleaner),
+// notify sends a notification to the channels configured in the policy, if any
+func (c *Context) notify(ctx context.Context, severity, title, text string) {
+	if c.Notifier == nil || c.Policy.Spec.NotificationConfig == nil {
+		return
+	}
+
+	msg := notification.Message{
+		Policy:    c.Policy.Name,
+		Namespace: c.Policy.Namespace,
+		Severity:  severity,
+		Title:     title,
+		Text:      text,
+	}
+	if err := c.Notifier.Send(ctx, c.Policy.Spec.NotificationConfig, msg); err != nil {
+		c.Logger.Error(err, "Failed to send notification", "title", title)
 	}
+}
 
-	// Register all cleaners
-	engine.registerCleaners()
-	return engine
+// CleanerConfig returns the configuration decoded for the named cleaner from spec.cleanup.extensions,
+// or nil if the cleaner has none
+func (c *Context) CleanerConfig(name string) interface{} {
+	return c.configs[name]
 }
 
-// registerCleaners registers all available cleaners
-func (e *Engine) registerCleaners() {
-	cleaners := []Cleaner{
-		NewPVCCleaner(),
-		NewJobsCleaner(),
-		NewConfigMapsCleaner(),
-		NewSecretsCleaner(),
-		NewServicesCleaner(),
-		NewTLSSecretsCleaner(),
-		NewTerminatingPodsCleaner(),
-		NewCrashLoopPodsCleaner(),
-		NewResourceGapsChecker(),
-		NewRBACChecker(),
-		NewStaleHelmReleasesCleaner(),
-	}
+// Findings returns the findings reported so far
+func (c *Context) Findings() []opsv1alpha1.Finding {
+	c.findingsMu.Lock()
+	defer c.findingsMu.Unlock()
 
-	for _, cleaner := range cleaners {
-		e.cleaners[cleaner.Name()] = cleaner
+	return append([]opsv1alpha1.Finding(nil), c.findings...)
+}
+
+// Engine handles the cleanup execution
+type Engine struct {
+	cleaners []Cleaner
+	locks    *ResourceLocks
+	pool     *WorkerPool
+}
+
+// Cleaner interface defines the cleanup behavior for specific resource types
+type Cleaner interface {
+	// Name identifies the cleaner in stats, logs and spec.cleanup.extensions
+	Name() string
+	// Enabled reports whether the policy enables the cleaner
+	Enabled(policy *opsv1alpha1.JanitorPolicy) bool
+	// Plan finds the resources to change without modifying anything. The engine applies the
+	// returned candidates, so cleaners never delete or patch resources themselves.
+	Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error)
+}
+
+// NewEngine creates a new cleanup engine running the cleaners registered so far on the pool
+func NewEngine(pool *WorkerPool) *Engine {
+	return &Engine{
+		cleaners: Registered(),
+		locks:    NewResourceLocks(),
+		pool:     pool,
 	}
 }
 
-// Execute runs the cleanup process based on the policy configuration
+// Execute runs the cleanup process based on the policy configuration. All enabled cleaners
+// plan first; the safeguards then run once against the complete plan before it is applied.
+// In dry-run mode the plan is only reported.
 func (e *Engine) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.CleanupStats, error) {
 	log := cleanupCtx.Logger.WithName("cleanup-engine")
+	start := time.Now()
 
 	stats := &opsv1alpha1.CleanupStats{
 		ByResourceType: make(map[string]opsv1alpha1.ResourceTypeStats),
@@ -74,131 +129,246 @@ func (e *Engine) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1
 
 	log.Info("Starting cleanup execution", "dryRun", cleanupCtx.DryRun)
 
-	// Execute PVC cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.PVC != nil && cleanupCtx.Policy.Spec.Cleanup.PVC.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "pvc", stats); err != nil {
-			log.Error(err, "PVC cleanup failed")
-			stats.ErrorsEncountered++
-		}
-	}
+	// Resources claimed by this run are released when it finishes
+	cleanupCtx.locks = e.locks
+	defer e.locks.releaseAll(cleanupCtx)
 
-	// Execute Jobs cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.Jobs != nil && cleanupCtx.Policy.Spec.Cleanup.Jobs.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "jobs", stats); err != nil {
-			log.Error(err, "Jobs cleanup failed")
-			stats.ErrorsEncountered++
-		}
-	}
+	// Decode the configuration of out-of-tree cleaners
+	configs, configErrs := decodeConfigs(e.cleaners, cleanupCtx.Policy)
+	cleanupCtx.configs = configs
 
-	// Execute ConfigMaps cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.ConfigMaps != nil && cleanupCtx.Policy.Spec.Cleanup.ConfigMaps.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "configmaps", stats); err != nil {
-			log.Error(err, "ConfigMaps cleanup failed")
+	// Plan the enabled cleaners concurrently
+	var enabled []Cleaner
+	for _, cleaner := range e.cleaners {
+		if err, invalid := configErrs[cleaner.Name()]; invalid {
+			log.Error(err, "Skipping cleaner with invalid configuration", "cleaner", cleaner.Name())
 			stats.ErrorsEncountered++
+			continue
 		}
-	}
-
-	// Execute Secrets cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.Secrets != nil && cleanupCtx.Policy.Spec.Cleanup.Secrets.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "secrets", stats); err != nil {
-			log.Error(err, "Secrets cleanup failed")
-			stats.ErrorsEncountered++
+		if cleaner.Enabled(cleanupCtx.Policy) {
+			enabled = append(enabled, cleaner)
 		}
 	}
 
-	// Execute Services cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.Services != nil && cleanupCtx.Policy.Spec.Cleanup.Services.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "services", stats); err != nil {
-			log.Error(err, "Services cleanup failed")
+	plans := make([][]Candidate, len(enabled))
+	planStats := make([]*opsv1alpha1.ResourceTypeStats, len(enabled))
+	planErrs := make([]error, len(enabled))
+	e.pool.Run(ctx, len(enabled), func(i int) {
+		plans[i], planStats[i], planErrs[i] = e.planCleaner(ctx, cleanupCtx, enabled[i])
+	})
+
+	// Combine the plans in registration order
+	var candidates []Candidate
+	statsByCleaner := make(map[string]*opsv1alpha1.ResourceTypeStats)
+	for i, cleaner := range enabled {
+		if planErrs[i] != nil {
+			log.Error(planErrs[i], "Cleaner failed", "cleaner", cleaner.Name())
 			stats.ErrorsEncountered++
 		}
-	}
-
-	// Execute TLS Secrets cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.TLSSecrets != nil && cleanupCtx.Policy.Spec.Cleanup.TLSSecrets.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "tlssecrets", stats); err != nil {
-			log.Error(err, "TLS Secrets cleanup failed")
-			stats.ErrorsEncountered++
+		if planStats[i] == nil {
+			planStats[i] = &opsv1alpha1.ResourceTypeStats{}
 		}
+		statsByCleaner[cleaner.Name()] = planStats[i]
+		candidates = append(candidates, plans[i]...)
 	}
 
-	// Execute Terminating Pods cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.TerminatingPods != nil && cleanupCtx.Policy.Spec.Cleanup.TerminatingPods.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "terminatingpods", stats); err != nil {
-			log.Error(err, "Terminating Pods cleanup failed")
-			stats.ErrorsEncountered++
-		}
+	log.Info("Cleanup plan completed", "candidates", len(candidates))
+
+	// Safeguards run once against the complete plan, before anything is changed.
+	// Destructive actions are only performed inside the maintenance windows.
+	if state, reason := MaintenanceState(cleanupCtx.Policy, time.Now()); state != MaintenanceWindowOpen && !cleanupCtx.DryRun {
+		log.Info("Maintenance window is not open, downgrading to dry-run", "state", state, "reason", reason)
+		cleanupCtx.EventRecorder.Event(cleanupCtx.Policy, "Normal", "DryRun", "Running as dry-run: "+reason)
+		cleanupCtx.DryRun = true
+		stats.DowngradedToDryRun = true
 	}
 
-	// Execute Crash Loop Pods handling
-	if cleanupCtx.Policy.Spec.Cleanup.CrashLoopPods != nil && cleanupCtx.Policy.Spec.Cleanup.CrashLoopPods.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "crashlooppods", stats); err != nil {
-			log.Error(err, "Crash Loop Pods handling failed")
-			stats.ErrorsEncountered++
-		}
+	// A plan exceeding the deletion budget is not applied at all, rather than partially
+	if exceeded := checkBudget(cleanupCtx.Policy.Spec.Budget, candidates, statsByCleaner); exceeded != "" {
+		log.Info("Deletion budget exceeded, not applying the plan", "reason", exceeded)
+		stats.BudgetExceeded = exceeded
+		cleanupCtx.EventRecorder.Event(cleanupCtx.Policy, "Warning", "BudgetExceeded", "Deletion budget exceeded, the plan is not applied: "+exceeded)
 	}
 
-	// Execute Resource Gaps check
-	if cleanupCtx.Policy.Spec.Cleanup.ResourceGaps != nil && cleanupCtx.Policy.Spec.Cleanup.ResourceGaps.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "resourcegaps", stats); err != nil {
-			log.Error(err, "Resource Gaps check failed")
-			stats.ErrorsEncountered++
-		}
+	// One notification covers the complete plan and its outcome
+	e.notifyPlan(ctx, cleanupCtx, enabled, candidates, stats)
+
+	// Runs older than the backup retention are pruned, whether or not this run deletes anything
+	if removed, err := sweepBackups(cleanupCtx.Policy, start); err != nil {
+		log.Error(err, "Failed to prune expired backups")
+	} else if removed > 0 {
+		log.Info("Pruned expired backups", "runs", removed)
 	}
 
-	// Execute RBAC check
-	if cleanupCtx.Policy.Spec.Cleanup.RBACCheck != nil && cleanupCtx.Policy.Spec.Cleanup.RBACCheck.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "rbaccheck", stats); err != nil {
-			log.Error(err, "RBAC check failed")
-			stats.ErrorsEncountered++
+	var applyErr error
+	switch {
+	case cleanupCtx.DryRun:
+		for i := range candidates {
+			e.report(cleanupCtx, &candidates[i])
+		}
+		for name, count := range countObjects(candidates) {
+			statsByCleaner[name].Cleaned += count
+		}
+	case stats.BudgetExceeded != "":
+		for name, count := range countObjects(candidates) {
+			statsByCleaner[name].Skipped += count
+		}
+	default:
+		// Nothing is deleted unless every deleted object can be backed up first
+		if cleanupCtx.backup, applyErr = newBackup(cleanupCtx.Policy, cleanupCtx.Client.Scheme(), start); applyErr != nil {
+			log.Error(applyErr, "Backup is not available, not applying the plan")
+			for name, count := range countObjects(candidates) {
+				statsByCleaner[name].Skipped += count
+			}
+			break
 		}
+		applyErr = e.apply(ctx, cleanupCtx, candidates, applyStages(enabled), statsByCleaner)
 	}
 
-	// Execute Stale Helm Releases cleanup
-	if cleanupCtx.Policy.Spec.Cleanup.StaleHelmReleases != nil && cleanupCtx.Policy.Spec.Cleanup.StaleHelmReleases.Enabled {
-		if err := e.executeCleaner(ctx, cleanupCtx, "stalehelm", stats); err != nil {
-			log.Error(err, "Stale Helm Releases cleanup failed")
-			stats.ErrorsEncountered++
-		}
+	for _, cleaner := range enabled {
+		resourceStats := statsByCleaner[cleaner.Name()]
+		stats.ResourcesScanned += resourceStats.Scanned
+		stats.ResourcesCleaned += resourceStats.Cleaned
+		stats.ErrorsEncountered += resourceStats.Errors
+		stats.ByResourceType[cleaner.Name()] = *resourceStats
 	}
 
+	stats.Findings = cleanupCtx.Findings()
+
 	log.Info("Cleanup execution completed",
 		"totalScanned", stats.ResourcesScanned,
 		"totalCleaned", stats.ResourcesCleaned,
 		"totalErrors", stats.ErrorsEncountered)
 
+	if err := ctx.Err(); err != nil {
+		return stats, fmt.Errorf("cleanup run cancelled: %w", err)
+	}
+	if applyErr != nil {
+		return stats, fmt.Errorf("cleanup run aborted: %w", applyErr)
+	}
+
 	return stats, nil
 }
 
-// executeCleaner executes a specific cleaner and updates stats
-func (e *Engine) executeCleaner(ctx context.Context, cleanupCtx *Context, cleanerName string, stats *opsv1alpha1.CleanupStats) error {
-	cleaner, exists := e.cleaners[cleanerName]
-	if !exists {
-		return fmt.Errorf("cleaner %s not found", cleanerName)
+// notifyPlan sends a single notification describing the plan, how it is handled and the alerts
+// queued by the cleaners. Nothing is sent for an empty plan without alerts.
+func (e *Engine) notifyPlan(ctx context.Context, cleanupCtx *Context, cleaners []Cleaner, candidates []Candidate, stats *opsv1alpha1.CleanupStats) {
+	cleanupCtx.findingsMu.Lock()
+	alerts := append([]string(nil), cleanupCtx.alerts...)
+	cleanupCtx.findingsMu.Unlock()
+
+	counts := countObjects(candidates)
+	var total int32
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 && len(alerts) == 0 {
+		return
+	}
+
+	severity := notification.SeverityInfo
+	if len(alerts) > 0 || stats.BudgetExceeded != "" {
+		severity = notification.SeverityWarning
+	}
+
+	var title string
+	switch {
+	case cleanupCtx.DryRun:
+		title = fmt.Sprintf("Cleanup plan: %d resource(s) would be changed (dry-run)", total)
+	case stats.BudgetExceeded != "":
+		title = fmt.Sprintf("Deletion budget exceeded: %d planned resource(s) not changed", total)
+	default:
+		title = fmt.Sprintf("Cleanup plan: changing %d resource(s)", total)
+	}
+
+	var lines []string
+	for _, cleaner := range cleaners {
+		if count := counts[cleaner.Name()]; count > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %d", cleaner.Name(), count))
+		}
+	}
+	if stats.BudgetExceeded != "" {
+		lines = append(lines, "", "Budget: "+stats.BudgetExceeded)
+	}
+	if len(alerts) > 0 {
+		lines = append(lines, "", fmt.Sprintf("Alerts (%d):", len(alerts)))
+		lines = append(lines, alerts...)
 	}
 
+	cleanupCtx.notify(ctx, severity, title, strings.TrimSpace(strings.Join(lines, "\n")))
+}
+
+// planCleaner runs the planning phase of a specific cleaner and tags its candidates
+func (e *Engine) planCleaner(ctx context.Context, cleanupCtx *Context, cleaner Cleaner) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	cleanerName := cleaner.Name()
 	log := cleanupCtx.Logger.WithName(fmt.Sprintf("cleaner-%s", cleanerName))
-	log.Info("Executing cleaner")
+
+	// Remaining cleaners are skipped once the run is cancelled
+	if ctx.Err() != nil {
+		log.Info("Cleanup run cancelled, skipping cleaner")
+		return nil, &opsv1alpha1.ResourceTypeStats{}, nil
+	}
+
+	log.Info("Planning cleaner")
 
 	start := time.Now()
-	resourceStats, err := cleaner.Execute(ctx, cleanupCtx)
+	candidates, resourceStats, err := cleaner.Plan(ctx, cleanupCtx)
 	duration := time.Since(start)
 
-	if resourceStats != nil {
-		stats.ResourcesScanned += resourceStats.Scanned
-		stats.ResourcesCleaned += resourceStats.Cleaned
-		stats.ErrorsEncountered += resourceStats.Errors
-		stats.ByResourceType[cleanerName] = *resourceStats
+	if resourceStats == nil {
+		resourceStats = &opsv1alpha1.ResourceTypeStats{}
+	}
+	for i := range candidates {
+		candidates[i].Cleaner = cleanerName
 	}
 
-	log.Info("Cleaner execution completed",
+	log.Info("Cleaner planning completed",
 		"duration", duration,
 		"scanned", resourceStats.Scanned,
-		"cleaned", resourceStats.Cleaned,
+		"candidates", len(candidates),
 		"errors", resourceStats.Errors,
 		"skipped", resourceStats.Skipped)
 
-	return err
+	return candidates, resourceStats, err
+}
+
+// applyStages assigns every cleaner the stage in which its changes are applied. A cleaner is
+// applied one stage after the latest of the cleaners it must follow; cleaners that are not
+// enabled are ignored, and ordering cycles are broken by ignoring the edge that closes them.
+func applyStages(cleaners []Cleaner) map[string]int {
+	byName := make(map[string]Cleaner)
+	for _, cleaner := range cleaners {
+		byName[cleaner.Name()] = cleaner
+	}
+
+	stages := make(map[string]int)
+	visiting := make(map[string]bool)
+	var stageOf func(name string) int
+	stageOf = func(name string) int {
+		if stage, done := stages[name]; done {
+			return stage
+		}
+		visiting[name] = true
+		stage := 0
+		if ordered, ok := byName[name].(OrderedCleaner); ok {
+			for _, before := range ordered.After() {
+				if _, exists := byName[before]; !exists || visiting[before] {
+					continue
+				}
+				if next := stageOf(before) + 1; next > stage {
+					stage = next
+				}
+			}
+		}
+		visiting[name] = false
+		stages[name] = stage
+		return stage
+	}
+
+	for _, cleaner := range cleaners {
+		stageOf(cleaner.Name())
+	}
+	return stages
 }
 
 // IsProtected checks if a resource is protected based on labels
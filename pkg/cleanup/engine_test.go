@@ -0,0 +1,99 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testCleaner is a cleaner returning a fixed plan and applied after the named cleaners
+type testCleaner struct {
+	name       string
+	after      []string
+	candidates []Candidate
+}
+
+func (c *testCleaner) Name() string {
+	return c.name
+}
+
+func (c *testCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return true
+}
+
+func (c *testCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	return c.candidates, &opsv1alpha1.ResourceTypeStats{Scanned: int32(len(c.candidates))}, nil
+}
+
+func (c *testCleaner) After() []string {
+	return c.after
+}
+
+func TestApplyStages(t *testing.T) {
+	tests := []struct {
+		name     string
+		cleaners []Cleaner
+		want     map[string]int
+	}{
+		{
+			name:     "unordered cleaners share the first stage",
+			cleaners: []Cleaner{&testCleaner{name: "a"}, &testCleaner{name: "b"}},
+			want:     map[string]int{"a": 0, "b": 0},
+		},
+		{
+			name: "chain",
+			cleaners: []Cleaner{
+				&testCleaner{name: "c", after: []string{"b"}},
+				&testCleaner{name: "b", after: []string{"a"}},
+				&testCleaner{name: "a"},
+			},
+			want: map[string]int{"a": 0, "b": 1, "c": 2},
+		},
+		{
+			name: "after the latest dependency",
+			cleaners: []Cleaner{
+				&testCleaner{name: "a"},
+				&testCleaner{name: "b", after: []string{"a"}},
+				&testCleaner{name: "c", after: []string{"a", "b"}},
+				&testCleaner{name: "d", after: []string{"a"}},
+			},
+			want: map[string]int{"a": 0, "b": 1, "c": 2, "d": 1},
+		},
+		{
+			name: "cleaners that are not enabled are ignored",
+			cleaners: []Cleaner{
+				&testCleaner{name: "pvc", after: []string{"terminatingpods", "crashlooppods"}},
+				&testCleaner{name: "crashlooppods"},
+			},
+			want: map[string]int{"crashlooppods": 0, "pvc": 1},
+		},
+		{
+			name: "cycle is broken",
+			cleaners: []Cleaner{
+				&testCleaner{name: "a", after: []string{"b"}},
+				&testCleaner{name: "b", after: []string{"a"}},
+			},
+			want: map[string]int{"a": 1, "b": 0},
+		},
+		{
+			name:     "self reference is ignored",
+			cleaners: []Cleaner{&testCleaner{name: "a", after: []string{"a"}}},
+			want:     map[string]int{"a": 0},
+		},
+		{
+			name:     "built-in cleaners",
+			cleaners: []Cleaner{NewPVCCleaner(), NewTerminatingPodsCleaner(), NewCrashLoopPodsCleaner(), NewJobsCleaner()},
+			want:     map[string]int{"pvc": 1, "terminatingpods": 0, "crashlooppods": 0, "jobs": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyStages(tt.cleaners); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got stages %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
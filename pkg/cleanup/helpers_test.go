@@ -0,0 +1,23 @@
+package cleanup
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// newTestContext returns a cleanup context for the policy backed by a fake client holding the objects
+func newTestContext(policy *opsv1alpha1.JanitorPolicy, objects ...client.Object) *Context {
+	return &Context{
+		Client:        fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+		Policy:        policy,
+		DryRun:        policy.Spec.DryRun,
+		Logger:        logr.Discard(),
+		EventRecorder: record.NewFakeRecorder(100),
+		locks:         NewResourceLocks(),
+	}
+}
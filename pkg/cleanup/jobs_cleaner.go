@@ -2,9 +2,14 @@ package cleanup
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
 )
@@ -22,14 +27,19 @@ func (c *JobsCleaner) Name() string {
 	return "jobs"
 }
 
-// Execute performs Jobs cleanup
-func (c *JobsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
+// Enabled reports whether the policy enables Jobs cleanup
+func (c *JobsCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.Jobs != nil && policy.Spec.Cleanup.Jobs.Enabled
+}
+
+// Plan finds old Jobs to delete
+func (c *JobsCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
 	log := cleanupCtx.Logger.WithName("jobs-cleaner")
 	stats := &opsv1alpha1.ResourceTypeStats{}
 
 	config := cleanupCtx.Policy.Spec.Cleanup.Jobs
 	if config == nil || !config.Enabled {
-		return stats, nil
+		return nil, stats, nil
 	}
 
 	// Parse duration
@@ -37,7 +47,7 @@ func (c *JobsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1a
 	if err != nil {
 		log.Error(err, "Failed to parse olderThan duration", "duration", config.OlderThan)
 		stats.Errors++
-		return stats, err
+		return nil, stats, err
 	}
 
 	cutoffTime := time.Now().Add(-olderThan)
@@ -47,19 +57,31 @@ func (c *JobsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1a
 	if err := cleanupCtx.Client.List(ctx, &jobList); err != nil {
 		log.Error(err, "Failed to list Jobs")
 		stats.Errors++
-		return stats, err
+		return nil, stats, err
 	}
 
 	stats.Scanned = int32(len(jobList.Items))
 
+	// Determine the newest successful and failed Jobs to keep per group
+	retained := c.retainedJobs(jobList.Items, config)
+
 	// Process each Job
-	for _, job := range jobList.Items {
-		if c.shouldSkipJob(&job, config, cleanupCtx) {
+	var candidates []Candidate
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+		if c.shouldSkipJob(job, config, cleanupCtx) {
 			log.V(1).Info("Skipping Job", "name", job.Name, "namespace", job.Namespace)
 			stats.Skipped++
 			continue
 		}
 
+		// Retained history is kept regardless of age
+		if retained[job.UID] {
+			log.V(1).Info("Job is retained as history, skipping", "name", job.Name, "namespace", job.Namespace, "group", c.jobGroup(job, config))
+			stats.Skipped++
+			continue
+		}
+
 		// Check if Job is old enough
 		if job.CreationTimestamp.Time.After(cutoffTime) {
 			log.V(1).Info("Job is too new, skipping", "name", job.Name, "namespace", job.Namespace, "age", time.Since(job.CreationTimestamp.Time))
@@ -68,37 +90,32 @@ func (c *JobsCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1a
 		}
 
 		// Check if Job status matches cleanup criteria
-		if !c.shouldCleanupJobByStatus(&job, config) {
+		if !c.shouldCleanupJobByStatus(job, config) {
 			log.V(1).Info("Job status doesn't match cleanup criteria", "name", job.Name, "namespace", job.Namespace)
 			stats.Skipped++
 			continue
 		}
 
 		// Job is old enough and matches status criteria
-		if cleanupCtx.DryRun {
-			log.Info("Would delete old Job", "name", job.Name, "namespace", job.Namespace, "age", time.Since(job.CreationTimestamp.Time))
-			cleanupCtx.EventRecorder.Event(&job, "Normal", "DryRun", "Would delete old Job")
-		} else {
-			log.Info("Deleting old Job", "name", job.Name, "namespace", job.Namespace, "age", time.Since(job.CreationTimestamp.Time))
-			if err := cleanupCtx.Client.Delete(ctx, &job); err != nil {
-				log.Error(err, "Failed to delete Job", "name", job.Name, "namespace", job.Namespace)
-				stats.Errors++
-				cleanupCtx.EventRecorder.Event(&job, "Warning", "DeleteFailed", "Failed to delete old Job")
-				continue
-			}
-			cleanupCtx.EventRecorder.Event(&job, "Normal", "Deleted", "Deleted old Job")
-		}
-
-		stats.Cleaned++
+		candidates = append(candidates, Candidate{
+			Object:        job,
+			Kind:          "Job",
+			Description:   "old Job",
+			Action:        ActionDelete,
+			Reason:        "Job status is " + c.jobStatus(job),
+			Age:           time.Since(job.CreationTimestamp.Time),
+			Rule:          "olderThan=" + config.OlderThan,
+			DeleteOptions: []client.DeleteOption{client.PropagationPolicy(metav1.DeletePropagationBackground)},
+		})
 	}
 
-	log.Info("Jobs cleanup completed",
+	log.Info("Jobs planning completed",
 		"scanned", stats.Scanned,
-		"cleaned", stats.Cleaned,
+		"candidates", len(candidates),
 		"skipped", stats.Skipped,
 		"errors", stats.Errors)
 
-	return stats, nil
+	return candidates, stats, nil
 }
 
 // shouldSkipJob determines if a Job should be skipped
@@ -152,6 +169,90 @@ func (c *JobsCleaner) shouldCleanupJobByStatus(job *batchv1.Job, config *opsv1al
 	return false
 }
 
+// retainedJobs returns the UIDs of the newest KeepSuccessfulJobs successful and KeepFailedJobs failed Jobs per group
+func (c *JobsCleaner) retainedJobs(jobs []batchv1.Job, config *opsv1alpha1.JobsCleanupConfig) map[types.UID]bool {
+	retained := make(map[types.UID]bool)
+	if config.KeepSuccessfulJobs == nil && config.KeepFailedJobs == nil {
+		return retained
+	}
+
+	successful := make(map[string][]*batchv1.Job)
+	failed := make(map[string][]*batchv1.Job)
+	for i := range jobs {
+		job := &jobs[i]
+		group := c.jobGroup(job, config)
+		switch {
+		case c.isJobComplete(job):
+			successful[group] = append(successful[group], job)
+		case c.isJobFailed(job):
+			failed[group] = append(failed[group], job)
+		}
+	}
+
+	keep := func(groups map[string][]*batchv1.Job, count *int32) {
+		if count == nil {
+			return
+		}
+		for _, groupJobs := range groups {
+			// Newest first
+			sort.Slice(groupJobs, func(i, j int) bool {
+				return c.finishedAt(groupJobs[i]).After(c.finishedAt(groupJobs[j]))
+			})
+			for i := 0; i < len(groupJobs) && i < int(*count); i++ {
+				retained[groupJobs[i].UID] = true
+			}
+		}
+	}
+	keep(successful, config.KeepSuccessfulJobs)
+	keep(failed, config.KeepFailedJobs)
+
+	return retained
+}
+
+// jobGroup returns the history group of a Job: its owning CronJob, the GroupByLabel value, or its namespace
+func (c *JobsCleaner) jobGroup(job *batchv1.Job, config *opsv1alpha1.JobsCleanupConfig) string {
+	for _, ref := range job.OwnerReferences {
+		if ref.Kind == "CronJob" {
+			return job.Namespace + "/cronjob/" + ref.Name
+		}
+	}
+	if config.GroupByLabel != "" {
+		if value, exists := job.Labels[config.GroupByLabel]; exists {
+			return job.Namespace + "/label/" + value
+		}
+	}
+	return job.Namespace
+}
+
+// finishedAt returns when a Job completed or failed, falling back to its start and creation time
+func (c *JobsCleaner) finishedAt(job *batchv1.Job) time.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobFailed || condition.Type == batchv1.JobComplete) && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	if job.Status.StartTime != nil {
+		return job.Status.StartTime.Time
+	}
+	return job.CreationTimestamp.Time
+}
+
+// jobStatus returns the status of a Job as used in JobsCleanupConfig.Statuses
+func (c *JobsCleaner) jobStatus(job *batchv1.Job) string {
+	switch {
+	case c.isJobComplete(job):
+		return "Complete"
+	case c.isJobFailed(job):
+		return "Failed"
+	case c.isJobActive(job):
+		return "Active"
+	}
+	return "Unknown"
+}
+
 // isJobComplete checks if a Job has completed successfully
 func (c *JobsCleaner) isJobComplete(job *batchv1.Job) bool {
 	for _, condition := range job.Status.Conditions {
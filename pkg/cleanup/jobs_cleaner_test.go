@@ -0,0 +1,145 @@
+package cleanup
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testJob describes a finished Job for retainedJobs
+type testJob struct {
+	name     string
+	cronJob  string
+	labels   map[string]string
+	failed   bool
+	finished time.Duration
+}
+
+func (j testJob) job(now time.Time) batchv1.Job {
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: j.name, Namespace: "default", UID: types.UID(j.name), Labels: j.labels},
+	}
+	if j.cronJob != "" {
+		job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: j.cronJob}}
+	}
+	conditionType := batchv1.JobComplete
+	if j.failed {
+		conditionType = batchv1.JobFailed
+	}
+	finished := metav1.NewTime(now.Add(-j.finished))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: finished}}
+	if !j.failed {
+		job.Status.CompletionTime = &finished
+	}
+	return job
+}
+
+func TestRetainedJobs(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		jobs   []testJob
+		config opsv1alpha1.JobsCleanupConfig
+		want   []string
+	}{
+		{
+			name: "no keep counts",
+			jobs: []testJob{{name: "a", finished: time.Hour}},
+			want: []string{},
+		},
+		{
+			name: "newest successful and failed per namespace",
+			jobs: []testJob{
+				{name: "old-ok", finished: 3 * time.Hour},
+				{name: "new-ok", finished: time.Hour},
+				{name: "old-failed", failed: true, finished: 4 * time.Hour},
+				{name: "new-failed", failed: true, finished: 2 * time.Hour},
+			},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](1), KeepFailedJobs: ptr.To[int32](1)},
+			want:   []string{"new-failed", "new-ok"},
+		},
+		{
+			name: "only successful jobs kept",
+			jobs: []testJob{
+				{name: "ok", finished: time.Hour},
+				{name: "failed", failed: true, finished: time.Hour},
+			},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](1)},
+			want:   []string{"ok"},
+		},
+		{
+			name: "grouped by owning CronJob",
+			jobs: []testJob{
+				{name: "backup-1", cronJob: "backup", finished: 2 * time.Hour},
+				{name: "backup-2", cronJob: "backup", finished: time.Hour},
+				{name: "report-1", cronJob: "report", finished: 3 * time.Hour},
+				{name: "manual", finished: 4 * time.Hour},
+			},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](1)},
+			want:   []string{"backup-2", "manual", "report-1"},
+		},
+		{
+			name: "grouped by label",
+			jobs: []testJob{
+				{name: "etl-1", labels: map[string]string{"app": "etl"}, finished: 2 * time.Hour},
+				{name: "etl-2", labels: map[string]string{"app": "etl"}, finished: time.Hour},
+				{name: "sync-1", labels: map[string]string{"app": "sync"}, finished: 3 * time.Hour},
+				{name: "unlabelled", finished: 4 * time.Hour},
+			},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](1), GroupByLabel: "app"},
+			want:   []string{"etl-2", "sync-1", "unlabelled"},
+		},
+		{
+			name: "CronJob owner wins over label",
+			jobs: []testJob{
+				{name: "owned", cronJob: "backup", labels: map[string]string{"app": "etl"}, finished: 2 * time.Hour},
+				{name: "labelled", labels: map[string]string{"app": "etl"}, finished: time.Hour},
+			},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](1), GroupByLabel: "app"},
+			want:   []string{"labelled", "owned"},
+		},
+		{
+			name: "keep count larger than group",
+			jobs: []testJob{
+				{name: "a", finished: 2 * time.Hour},
+				{name: "b", finished: time.Hour},
+			},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](5)},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "keep count of zero",
+			jobs:   []testJob{{name: "a", finished: time.Hour}},
+			config: opsv1alpha1.JobsCleanupConfig{KeepSuccessfulJobs: ptr.To[int32](0)},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := make([]batchv1.Job, 0, len(tt.jobs))
+			for _, job := range tt.jobs {
+				jobs = append(jobs, job.job(now))
+			}
+
+			got := []string{}
+			for uid := range NewJobsCleaner().retainedJobs(jobs, &tt.config) {
+				got = append(got, string(uid))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("retained %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
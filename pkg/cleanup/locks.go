@@ -0,0 +1,55 @@
+package cleanup
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceLocks tracks which cleanup run is acting on a resource, so that runs of
+// different policies never modify or delete the same object concurrently
+type ResourceLocks struct {
+	mu     sync.Mutex
+	owners map[types.UID]*Context
+}
+
+// NewResourceLocks creates an empty set of resource locks
+func NewResourceLocks() *ResourceLocks {
+	return &ResourceLocks{
+		owners: make(map[types.UID]*Context),
+	}
+}
+
+// claim locks the resource for the run. Claiming a resource the run already holds succeeds.
+func (l *ResourceLocks) claim(uid types.UID, run *Context) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if owner, held := l.owners[uid]; held && owner != run {
+		return false
+	}
+	l.owners[uid] = run
+	return true
+}
+
+// releaseAll releases every resource held by the run
+func (l *ResourceLocks) releaseAll(run *Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for uid, owner := range l.owners {
+		if owner == run {
+			delete(l.owners, uid)
+		}
+	}
+}
+
+// Claim locks obj for the remainder of the cleanup run. It returns false if a
+// concurrent run, of this or another policy, is already acting on the object.
+func (c *Context) Claim(obj client.Object) bool {
+	if c.locks == nil || obj.GetUID() == "" {
+		return true
+	}
+	return c.locks.claim(obj.GetUID(), c)
+}
@@ -0,0 +1,107 @@
+package cleanup
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestResourceLocks(t *testing.T) {
+	// step claims or releases a resource for one of two runs
+	type step struct {
+		run     int
+		uid     types.UID
+		release bool
+		want    bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "free resource",
+			steps: []step{{run: 0, uid: "a", want: true}},
+		},
+		{
+			name:  "claimed again by the same run",
+			steps: []step{{run: 0, uid: "a", want: true}, {run: 0, uid: "a", want: true}},
+		},
+		{
+			name:  "claimed by another run",
+			steps: []step{{run: 0, uid: "a", want: true}, {run: 1, uid: "a"}},
+		},
+		{
+			name:  "other resources stay free",
+			steps: []step{{run: 0, uid: "a", want: true}, {run: 1, uid: "b", want: true}},
+		},
+		{
+			name: "released when the run finishes",
+			steps: []step{
+				{run: 0, uid: "a", want: true},
+				{run: 0, uid: "b", want: true},
+				{run: 0, release: true},
+				{run: 1, uid: "a", want: true},
+				{run: 1, uid: "b", want: true},
+			},
+		},
+		{
+			name: "only the resources of the finished run are released",
+			steps: []step{
+				{run: 0, uid: "a", want: true},
+				{run: 1, uid: "b", want: true},
+				{run: 1, release: true},
+				{run: 1, uid: "a"},
+				{run: 0, uid: "b", want: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := NewResourceLocks()
+			runs := []*Context{
+				newTestContext(&opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "first"}}),
+				newTestContext(&opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "second"}}),
+			}
+
+			for i, s := range tt.steps {
+				if s.release {
+					locks.releaseAll(runs[s.run])
+					continue
+				}
+				if got := locks.claim(s.uid, runs[s.run]); got != s.want {
+					t.Errorf("step %d: run %d claiming %s got %v, want %v", i, s.run, s.uid, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestContextClaim(t *testing.T) {
+	first := newTestContext(&opsv1alpha1.JanitorPolicy{})
+	second := newTestContext(&opsv1alpha1.JanitorPolicy{})
+	second.locks = first.locks
+	unlocked := newTestContext(&opsv1alpha1.JanitorPolicy{})
+	unlocked.locks = nil
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", UID: "pod-uid"}}
+	unsaved := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "new"}}
+
+	if !first.Claim(pod) {
+		t.Fatal("free object was not claimed")
+	}
+	if second.Claim(pod) {
+		t.Error("object claimed by two runs sharing the locks")
+	}
+	if !unlocked.Claim(pod) {
+		t.Error("run without locks was refused")
+	}
+	if !first.Claim(unsaved) || !second.Claim(unsaved) {
+		t.Error("object without a UID was refused")
+	}
+}
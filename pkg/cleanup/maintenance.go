@@ -0,0 +1,132 @@
+package cleanup
+
+import (
+	"fmt"
+	"time"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// MaintenanceWindowOpen means destructive actions are allowed
+	MaintenanceWindowOpen = "Open"
+
+	// MaintenanceWindowClosed means the current time is outside every maintenance window
+	MaintenanceWindowClosed = "Closed"
+
+	// MaintenanceWindowBlackout means the current time is inside a blackout period
+	MaintenanceWindowBlackout = "Blackout"
+)
+
+// weekdays maps the day names used in maintenance windows
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// MaintenanceState reports whether the policy allows destructive actions at the given time,
+// together with a human readable explanation. Invalid configuration closes the window.
+func MaintenanceState(policy *opsv1alpha1.JanitorPolicy, now time.Time) (string, string) {
+	config := policy.Spec.Maintenance
+	if config == nil {
+		return MaintenanceWindowOpen, "no maintenance configuration"
+	}
+
+	zone := config.TimeZone
+	if zone == "" {
+		zone = policy.Spec.TimeZone
+	}
+	location := time.UTC
+	if zone != "" {
+		var err error
+		location, err = time.LoadLocation(zone)
+		if err != nil {
+			return MaintenanceWindowClosed, fmt.Sprintf("invalid maintenance time zone %q", zone)
+		}
+	}
+	local := now.In(location)
+
+	for _, blackout := range config.Blackouts {
+		start, err := time.ParseInLocation("2006-01-02", blackout.Start, location)
+		if err != nil {
+			return MaintenanceWindowClosed, fmt.Sprintf("invalid blackout start %q", blackout.Start)
+		}
+		end, err := time.ParseInLocation("2006-01-02", blackout.End, location)
+		if err != nil {
+			return MaintenanceWindowClosed, fmt.Sprintf("invalid blackout end %q", blackout.End)
+		}
+
+		// The end date is inclusive
+		if !local.Before(start) && local.Before(end.AddDate(0, 0, 1)) {
+			name := blackout.Name
+			if name == "" {
+				name = blackout.Start + " to " + blackout.End
+			}
+			return MaintenanceWindowBlackout, "inside blackout " + name
+		}
+	}
+
+	if len(config.Windows) == 0 {
+		return MaintenanceWindowOpen, "outside blackout periods"
+	}
+
+	for _, window := range config.Windows {
+		open, err := inMaintenanceWindow(window, local)
+		if err != nil {
+			return MaintenanceWindowClosed, err.Error()
+		}
+		if open {
+			return MaintenanceWindowOpen, fmt.Sprintf("inside maintenance window %s-%s", window.Start, window.End)
+		}
+	}
+
+	return MaintenanceWindowClosed, "outside maintenance windows"
+}
+
+// inMaintenanceWindow checks if the local time falls into the window. Windows ending at or
+// before their start span midnight and belong to the day they start on.
+func inMaintenanceWindow(window opsv1alpha1.MaintenanceWindow, local time.Time) (bool, error) {
+	start, err := minuteOfDay(window.Start)
+	if err != nil {
+		return false, err
+	}
+	end, err := minuteOfDay(window.End)
+	if err != nil {
+		return false, err
+	}
+
+	days := make(map[time.Weekday]bool)
+	for _, day := range window.Days {
+		weekday, exists := weekdays[day]
+		if !exists {
+			return false, fmt.Errorf("invalid maintenance window day %q", day)
+		}
+		days[weekday] = true
+	}
+	startsOn := func(day time.Weekday) bool {
+		return len(days) == 0 || days[day]
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return startsOn(local.Weekday()) && minute >= start && minute < end, nil
+	}
+
+	// Overnight window: the part after the start today, or the part before the end of yesterday's window
+	yesterday := (local.Weekday() + 6) % 7
+	return (startsOn(local.Weekday()) && minute >= start) || (startsOn(yesterday) && minute < end), nil
+}
+
+// minuteOfDay parses an HH:MM time of day
+func minuteOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maintenance window time %q", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,70 @@
+package cleanup
+
+import (
+	"testing"
+	"time"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestMaintenanceState(t *testing.T) {
+	weekdayNights := opsv1alpha1.MaintenanceWindow{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "22:00", End: "04:00"}
+	afternoon := opsv1alpha1.MaintenanceWindow{Start: "13:00", End: "15:00"}
+	freeze := opsv1alpha1.BlackoutPeriod{Name: "Year-end freeze", Start: "2024-12-20", End: "2025-01-02"}
+
+	tests := []struct {
+		name     string
+		config   *opsv1alpha1.MaintenanceConfig
+		timeZone string
+		now      string
+		want     string
+	}{
+		{name: "no configuration", now: "2024-06-03T12:00:00Z", want: MaintenanceWindowOpen},
+		{name: "no windows", config: &opsv1alpha1.MaintenanceConfig{}, now: "2024-06-03T12:00:00Z", want: MaintenanceWindowOpen},
+
+		// 2024-06-03 is a Monday
+		{name: "inside window", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, now: "2024-06-03T14:00:00Z", want: MaintenanceWindowOpen},
+		{name: "window start is inclusive", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, now: "2024-06-03T13:00:00Z", want: MaintenanceWindowOpen},
+		{name: "window end is exclusive", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, now: "2024-06-03T15:00:00Z", want: MaintenanceWindowClosed},
+		{name: "outside window", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, now: "2024-06-03T12:59:00Z", want: MaintenanceWindowClosed},
+		{name: "second window", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{weekdayNights, afternoon}}, now: "2024-06-03T14:00:00Z", want: MaintenanceWindowOpen},
+
+		{name: "overnight window before midnight", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{weekdayNights}}, now: "2024-06-03T23:00:00Z", want: MaintenanceWindowOpen},
+		{name: "overnight window after midnight", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{weekdayNights}}, now: "2024-06-04T03:59:00Z", want: MaintenanceWindowOpen},
+		{name: "overnight window started on Friday", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{weekdayNights}}, now: "2024-06-08T02:00:00Z", want: MaintenanceWindowOpen},
+		{name: "overnight window not started on Sunday", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{weekdayNights}}, now: "2024-06-03T02:00:00Z", want: MaintenanceWindowClosed},
+		{name: "overnight window not starting on Saturday", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{weekdayNights}}, now: "2024-06-08T23:00:00Z", want: MaintenanceWindowClosed},
+
+		{name: "window in policy time zone", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, timeZone: "Europe/Berlin", now: "2024-06-03T12:00:00Z", want: MaintenanceWindowOpen},
+		{name: "window outside policy time zone", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, timeZone: "Europe/Berlin", now: "2024-06-03T14:00:00Z", want: MaintenanceWindowClosed},
+		{name: "maintenance time zone overrides policy", config: &opsv1alpha1.MaintenanceConfig{TimeZone: "America/New_York", Windows: []opsv1alpha1.MaintenanceWindow{afternoon}}, timeZone: "Europe/Berlin", now: "2024-06-03T18:00:00Z", want: MaintenanceWindowOpen},
+		{name: "weekday in window time zone", config: &opsv1alpha1.MaintenanceConfig{TimeZone: "Asia/Tokyo", Windows: []opsv1alpha1.MaintenanceWindow{{Days: []string{"Tue"}, Start: "08:00", End: "10:00"}}}, now: "2024-06-03T23:30:00Z", want: MaintenanceWindowOpen},
+		{name: "invalid time zone", config: &opsv1alpha1.MaintenanceConfig{TimeZone: "Mars/Olympus"}, now: "2024-06-03T12:00:00Z", want: MaintenanceWindowClosed},
+		{name: "invalid day", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{{Days: []string{"Funday"}, Start: "00:00", End: "23:59"}}}, now: "2024-06-03T12:00:00Z", want: MaintenanceWindowClosed},
+		{name: "invalid time", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{{Start: "25:00", End: "23:59"}}}, now: "2024-06-03T12:00:00Z", want: MaintenanceWindowClosed},
+
+		{name: "blackout first day", config: &opsv1alpha1.MaintenanceConfig{Blackouts: []opsv1alpha1.BlackoutPeriod{freeze}}, now: "2024-12-20T00:00:00Z", want: MaintenanceWindowBlackout},
+		{name: "blackout end is inclusive", config: &opsv1alpha1.MaintenanceConfig{Blackouts: []opsv1alpha1.BlackoutPeriod{freeze}}, now: "2025-01-02T23:59:00Z", want: MaintenanceWindowBlackout},
+		{name: "after blackout", config: &opsv1alpha1.MaintenanceConfig{Blackouts: []opsv1alpha1.BlackoutPeriod{freeze}}, now: "2025-01-03T00:00:00Z", want: MaintenanceWindowOpen},
+		{name: "blackout wins over window", config: &opsv1alpha1.MaintenanceConfig{Windows: []opsv1alpha1.MaintenanceWindow{afternoon}, Blackouts: []opsv1alpha1.BlackoutPeriod{freeze}}, now: "2024-12-23T14:00:00Z", want: MaintenanceWindowBlackout},
+		{name: "blackout in time zone", config: &opsv1alpha1.MaintenanceConfig{TimeZone: "America/New_York", Blackouts: []opsv1alpha1.BlackoutPeriod{freeze}}, now: "2025-01-03T03:00:00Z", want: MaintenanceWindowBlackout},
+		{name: "invalid blackout", config: &opsv1alpha1.MaintenanceConfig{Blackouts: []opsv1alpha1.BlackoutPeriod{{Start: "2024-13-01", End: "2024-12-31"}}}, now: "2024-06-03T12:00:00Z", want: MaintenanceWindowClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.TimeZone = tt.timeZone
+			policy.Spec.Maintenance = tt.config
+
+			state, reason := MaintenanceState(policy, now)
+			if state != tt.want {
+				t.Errorf("got %s (%s), want %s", state, reason, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// Action is the change applied to a candidate
+type Action string
+
+const (
+	// ActionDelete deletes the object
+	ActionDelete Action = "Delete"
+
+	// ActionPatch changes the object through Candidate.Mutate and sends the result as a merge patch
+	ActionPatch Action = "Patch"
+)
+
+// Candidate is a resource a cleaner plans to change, together with why
+type Candidate struct {
+	// Cleaner - name of the cleaner that planned the change, set by the engine
+	Cleaner string
+
+	// Object - the resource to act on
+	Object client.Object
+
+	// Kind - kind of the object, for logs
+	Kind string
+
+	// Description - how the resource is named in events, e.g. "unused PVC"
+	Description string
+
+	// Action - the change to apply
+	Action Action
+
+	// Reason - why the resource is a candidate
+	Reason string
+
+	// Age - how long the resource has matched the rule
+	Age time.Duration
+
+	// Rule - the policy setting the resource matched, e.g. "unusedFor=48h"
+	Rule string
+
+	// DeleteOptions - options for the Delete call of ActionDelete candidates
+	DeleteOptions []client.DeleteOption
+
+	// Mutate - changes Object in place for ActionPatch candidates
+	Mutate func()
+}
+
+// verbs returns the present and past tense of the action for logs and events
+func (c *Candidate) verbs() (string, string) {
+	if c.Action == ActionPatch {
+		return "patch", "Patched"
+	}
+	return "delete", "Deleted"
+}
+
+// report records a planned change without applying it
+func (e *Engine) report(cleanupCtx *Context, candidate *Candidate) {
+	verb, _ := candidate.verbs()
+	obj := candidate.Object
+
+	cleanupCtx.Logger.Info("Would "+verb+" "+candidate.Description,
+		"cleaner", candidate.Cleaner,
+		"kind", candidate.Kind,
+		"name", obj.GetName(),
+		"namespace", obj.GetNamespace(),
+		"reason", candidate.Reason,
+		"rule", candidate.Rule,
+		"age", candidate.Age)
+	cleanupCtx.EventRecorder.Event(obj, "Normal", "DryRun", fmt.Sprintf("Would %s %s: %s", verb, candidate.Description, candidate.Reason))
+}
+
+// countObjects returns the number of distinct objects planned by each cleaner
+func countObjects(candidates []Candidate) map[string]int32 {
+	counts := make(map[string]int32)
+	counted := make(map[types.UID]bool)
+	for i := range candidates {
+		uid := candidates[i].Object.GetUID()
+		if uid != "" && counted[uid] {
+			continue
+		}
+		counted[uid] = true
+		counts[candidates[i].Cleaner]++
+	}
+	return counts
+}
+
+// apply performs the planned changes stage by stage. Within a stage the objects are changed
+// concurrently on the worker pool, while the changes to a single object are applied in order.
+// A failed backup aborts the run: changes not started yet are not applied and the error is returned.
+func (e *Engine) apply(ctx context.Context, cleanupCtx *Context, candidates []Candidate, stages map[string]int, statsByCleaner map[string]*opsv1alpha1.ResourceTypeStats) error {
+	runCtx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+
+	lastStage := 0
+	for _, stage := range stages {
+		if stage > lastStage {
+			lastStage = stage
+		}
+	}
+
+	for stage := 0; stage <= lastStage && runCtx.Err() == nil; stage++ {
+		var objects [][]*Candidate
+		index := make(map[types.UID]int)
+		for i := range candidates {
+			candidate := &candidates[i]
+			if stages[candidate.Cleaner] != stage {
+				continue
+			}
+			uid := candidate.Object.GetUID()
+			if position, exists := index[uid]; exists && uid != "" {
+				objects[position] = append(objects[position], candidate)
+				continue
+			}
+			index[uid] = len(objects)
+			objects = append(objects, []*Candidate{candidate})
+		}
+
+		e.pool.Run(runCtx, len(objects), func(i int) {
+			if err := e.applyObject(runCtx, cleanupCtx, objects[i], statsByCleaner); err != nil {
+				abort(err)
+			}
+		})
+	}
+
+	if ctx.Err() != nil {
+		cleanupCtx.Logger.Info("Cleanup run cancelled, not applying remaining changes")
+		return nil
+	}
+	if err := context.Cause(runCtx); err != nil {
+		cleanupCtx.Logger.Error(err, "Cleanup run aborted, not applying remaining changes")
+		return err
+	}
+	return nil
+}
+
+// applyObject performs the planned changes to one object in order. An object that is deleted is
+// backed up before its first change; a backup error is returned. Once a change fails, the
+// remaining changes are skipped. The object is counted as cleaned once. Stats are updated
+// atomically, as other objects are applied concurrently.
+func (e *Engine) applyObject(ctx context.Context, cleanupCtx *Context, candidates []*Candidate, statsByCleaner map[string]*opsv1alpha1.ResourceTypeStats) error {
+	cleaned := false
+	backedUp := cleanupCtx.backup == nil || !deletes(candidates)
+	for i, candidate := range candidates {
+		stats := statsByCleaner[candidate.Cleaner]
+		obj := candidate.Object
+		log := cleanupCtx.Logger.WithValues("cleaner", candidate.Cleaner, "kind", candidate.Kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+
+		if !cleanupCtx.Claim(obj) {
+			log.Info("Resource is being processed by another cleanup run, skipping")
+			atomic.AddInt32(&stats.Skipped, 1)
+			return nil
+		}
+
+		if !backedUp {
+			path, err := cleanupCtx.backup.write(obj)
+			if err != nil {
+				atomic.AddInt32(&stats.Errors, 1)
+				cleanupCtx.EventRecorder.Event(obj, "Warning", "BackupFailed", fmt.Sprintf("Failed to back up %s, not deleting it: %v", candidate.Description, err))
+				skip(candidates[i+1:], statsByCleaner)
+				return fmt.Errorf("failed to back up %s %s/%s: %w", candidate.Kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+			log.Info("Backed up resource", "path", path)
+			backedUp = true
+		}
+
+		if err := e.pool.throttle(ctx); err != nil {
+			return nil
+		}
+
+		verb, done := candidate.verbs()
+		log.Info("Applying planned change", "action", candidate.Action, "description", candidate.Description, "reason", candidate.Reason, "rule", candidate.Rule, "age", candidate.Age)
+
+		var err error
+		switch candidate.Action {
+		case ActionDelete:
+			// The plan was built from a snapshot, so an object recreated or changed since is left alone
+			err = cleanupCtx.Client.Delete(ctx, obj, append([]client.DeleteOption{preconditions(obj)}, candidate.DeleteOptions...)...)
+			if apierrors.IsNotFound(err) {
+				err = nil
+			}
+		case ActionPatch:
+			// A merge patch replaces whole lists, so it must not overwrite changes made since the plan
+			patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+			candidate.Mutate()
+			err = cleanupCtx.Client.Patch(ctx, obj, patch)
+		default:
+			err = fmt.Errorf("unknown action %q", candidate.Action)
+		}
+
+		if apierrors.IsConflict(err) {
+			log.Info("Resource changed since it was planned, skipping", "action", candidate.Action)
+			atomic.AddInt32(&stats.Skipped, 1)
+			skip(candidates[i+1:], statsByCleaner)
+			return nil
+		}
+		if err != nil {
+			log.Error(err, "Failed to "+verb+" "+candidate.Description)
+			atomic.AddInt32(&stats.Errors, 1)
+			cleanupCtx.EventRecorder.Event(obj, "Warning", string(candidate.Action)+"Failed", fmt.Sprintf("Failed to %s %s", verb, candidate.Description))
+			skip(candidates[i+1:], statsByCleaner)
+			return nil
+		}
+
+		cleanupCtx.EventRecorder.Event(obj, "Normal", done, fmt.Sprintf("%s %s: %s", done, candidate.Description, candidate.Reason))
+		if !cleaned {
+			cleaned = true
+			atomic.AddInt32(&stats.Cleaned, 1)
+		}
+	}
+	return nil
+}
+
+// preconditions limits a delete to the object as it was planned
+func preconditions(obj client.Object) client.Preconditions {
+	var preconditions client.Preconditions
+	if uid := obj.GetUID(); uid != "" {
+		preconditions.UID = &uid
+	}
+	if resourceVersion := obj.GetResourceVersion(); resourceVersion != "" {
+		preconditions.ResourceVersion = &resourceVersion
+	}
+	return preconditions
+}
+
+// deletes reports whether any of the changes deletes the object
+func deletes(candidates []*Candidate) bool {
+	for _, candidate := range candidates {
+		if candidate.Action == ActionDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// skip counts changes that are not applied because an earlier change to the object failed
+func skip(candidates []*Candidate, statsByCleaner map[string]*opsv1alpha1.ResourceTypeStats) {
+	for _, candidate := range candidates {
+		atomic.AddInt32(&statsByCleaner[candidate.Cleaner].Skipped, 1)
+	}
+}
@@ -0,0 +1,225 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testChange describes a planned change to a ConfigMap for apply
+type testChange struct {
+	cleaner string
+	name    string
+	action  Action
+	// stale plans the change against an older version of the ConfigMap
+	stale bool
+	// missing deletes the ConfigMap before the plan is applied
+	missing bool
+	// conflict makes the API server reject patches of the ConfigMap with a conflict
+	conflict bool
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		changes     []testChange
+		stages      map[string]int
+		backupFails bool
+		wantErr     bool
+		wantDeleted []string
+		wantStats   map[string]opsv1alpha1.ResourceTypeStats
+	}{
+		{
+			name:        "deletes planned objects",
+			changes:     []testChange{{cleaner: "a", name: "one", action: ActionDelete}, {cleaner: "a", name: "two", action: ActionDelete}},
+			wantDeleted: []string{"one", "two"},
+			wantStats:   map[string]opsv1alpha1.ResourceTypeStats{"a": {Cleaned: 2}},
+		},
+		{
+			name:      "object already deleted",
+			changes:   []testChange{{cleaner: "a", name: "one", action: ActionDelete, missing: true}},
+			wantStats: map[string]opsv1alpha1.ResourceTypeStats{"a": {Cleaned: 1}},
+		},
+		{
+			name:      "object changed since it was planned",
+			changes:   []testChange{{cleaner: "a", name: "one", action: ActionDelete, stale: true}},
+			wantStats: map[string]opsv1alpha1.ResourceTypeStats{"a": {Skipped: 1}},
+		},
+		{
+			name: "patch of an object changed since it was planned",
+			changes: []testChange{
+				{cleaner: "a", name: "one", action: ActionPatch, stale: true},
+				{cleaner: "a", name: "one", action: ActionDelete},
+			},
+			wantStats: map[string]opsv1alpha1.ResourceTypeStats{"a": {Skipped: 2}},
+		},
+		{
+			name:      "patch rejected with a conflict",
+			changes:   []testChange{{cleaner: "a", name: "one", action: ActionPatch, conflict: true}},
+			wantStats: map[string]opsv1alpha1.ResourceTypeStats{"a": {Skipped: 1}},
+		},
+		{
+			name: "changes to one object counted once",
+			changes: []testChange{
+				{cleaner: "a", name: "one", action: ActionPatch},
+				{cleaner: "a", name: "one", action: ActionDelete},
+			},
+			wantDeleted: []string{"one"},
+			wantStats:   map[string]opsv1alpha1.ResourceTypeStats{"a": {Cleaned: 1}},
+		},
+		{
+			name: "later stages applied after earlier ones",
+			changes: []testChange{
+				{cleaner: "late", name: "three", action: ActionDelete},
+				{cleaner: "early", name: "one", action: ActionDelete},
+				{cleaner: "middle", name: "two", action: ActionDelete},
+			},
+			stages:      map[string]int{"early": 0, "middle": 1, "late": 2},
+			wantDeleted: []string{"one", "two", "three"},
+			wantStats:   map[string]opsv1alpha1.ResourceTypeStats{"early": {Cleaned: 1}, "middle": {Cleaned: 1}, "late": {Cleaned: 1}},
+		},
+		{
+			name: "failed backup aborts the run",
+			changes: []testChange{
+				{cleaner: "early", name: "one", action: ActionDelete},
+				{cleaner: "early", name: "one", action: ActionDelete},
+				{cleaner: "late", name: "two", action: ActionDelete},
+			},
+			stages:      map[string]int{"early": 0, "late": 1},
+			backupFails: true,
+			wantErr:     true,
+			wantStats:   map[string]opsv1alpha1.ResourceTypeStats{"early": {Errors: 1, Skipped: 1}, "late": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			var mu sync.Mutex
+			deleted := []string{}
+			objects := make(map[string]*corev1.ConfigMap)
+			conflicts := make(map[string]bool)
+			for _, change := range tt.changes {
+				conflicts[change.name] = conflicts[change.name] || change.conflict
+				objects[change.name] = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: change.name, Namespace: "default", UID: types.UID(change.name)}}
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			for _, obj := range objects {
+				builder = builder.WithObjects(obj)
+			}
+			c := builder.WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					err := c.Delete(ctx, obj, opts...)
+					if err == nil {
+						mu.Lock()
+						deleted = append(deleted, obj.GetName())
+						mu.Unlock()
+					}
+					return err
+				},
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if conflicts[obj.GetName()] {
+						return apierrors.NewConflict(corev1.Resource("configmaps"), obj.GetName(), errors.New("object has been modified"))
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+
+			// Plan against the stored objects, then change the cluster underneath the plan
+			// Changes to the same object share it, as in the plans of the cleaners
+			var candidates []Candidate
+			statsByCleaner := make(map[string]*opsv1alpha1.ResourceTypeStats)
+			plannedObjects := make(map[string]*corev1.ConfigMap)
+			for _, change := range tt.changes {
+				planned, exists := plannedObjects[change.name]
+				if !exists {
+					planned = &corev1.ConfigMap{}
+					if err := c.Get(ctx, client.ObjectKeyFromObject(objects[change.name]), planned); err != nil {
+						t.Fatal(err)
+					}
+					plannedObjects[change.name] = planned
+				}
+				candidates = append(candidates, Candidate{
+					Cleaner: change.cleaner,
+					Object:  planned,
+					Kind:    "ConfigMap",
+					Action:  change.action,
+					Mutate:  func() { planned.Data = map[string]string{"patched": "true"} },
+				})
+				statsByCleaner[change.cleaner] = &opsv1alpha1.ResourceTypeStats{}
+			}
+			for _, change := range tt.changes {
+				current := &corev1.ConfigMap{}
+				err := c.Get(ctx, client.ObjectKeyFromObject(objects[change.name]), current)
+				switch {
+				case apierrors.IsNotFound(err):
+				case err != nil:
+					t.Fatal(err)
+				case change.missing:
+					if err := c.Delete(ctx, current); err != nil {
+						t.Fatal(err)
+					}
+				case change.stale:
+					current.Labels = map[string]string{"changed": "true"}
+					if err := c.Update(ctx, current); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			deleted = []string{}
+
+			cleanupCtx := newTestContext(&opsv1alpha1.JanitorPolicy{})
+			cleanupCtx.Client = c
+			if tt.backupFails {
+				// A directory cannot be created below a regular file
+				file := filepath.Join(t.TempDir(), "file")
+				if err := os.WriteFile(file, nil, 0o600); err != nil {
+					t.Fatal(err)
+				}
+				cleanupCtx.backup = &backup{dir: filepath.Join(file, "run"), scheme: scheme.Scheme}
+			}
+			stages := tt.stages
+			if stages == nil {
+				stages = map[string]int{}
+			}
+
+			engine := &Engine{locks: cleanupCtx.locks, pool: NewWorkerPool(4, 0, 0)}
+			err := engine.apply(ctx, cleanupCtx, candidates, stages, statsByCleaner)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+
+			// Only changes in different stages are applied in a defined order
+			wantDeleted := append([]string{}, tt.wantDeleted...)
+			if tt.stages == nil {
+				sort.Strings(deleted)
+				sort.Strings(wantDeleted)
+			}
+			if !reflect.DeepEqual(deleted, wantDeleted) {
+				t.Errorf("deleted %v, want %v", deleted, wantDeleted)
+			}
+			for cleaner, want := range tt.wantStats {
+				if got := *statsByCleaner[cleaner]; got != want {
+					t.Errorf("cleaner %s: got stats %+v, want %+v", cleaner, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cleanup
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// WorkerPool bounds the work of all cleanup runs of the operator. Its workers are shared by
+// every policy, so concurrent runs together never exceed the configured parallelism and rate
+// of API writes.
+type WorkerPool struct {
+	slots   chan struct{}
+	limiter flowcontrol.RateLimiter
+}
+
+// NewWorkerPool creates a pool running up to parallelism tasks at once. Writes to the API server
+// are limited to qps per second with bursts of up to burst; a qps of 0 disables the rate limit.
+func NewWorkerPool(parallelism int, qps float32, burst int) *WorkerPool {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	pool := &WorkerPool{
+		slots: make(chan struct{}, parallelism),
+	}
+	if qps > 0 {
+		if burst < 1 {
+			burst = 1
+		}
+		pool.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	}
+	return pool
+}
+
+// Run calls task for every index in [0, n) on the pool's workers and waits for all of them.
+// Tasks that have not started when the context is cancelled are not run.
+func (p *WorkerPool) Run(ctx context.Context, n int, task func(i int)) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i := 0; i < n; i++ {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-p.slots }()
+			task(i)
+		}(i)
+	}
+}
+
+// throttle blocks until the rate limit allows another write to the API server
+func (p *WorkerPool) throttle(ctx context.Context) error {
+	if p.limiter == nil {
+		return ctx.Err()
+	}
+	return p.limiter.Wait(ctx)
+}
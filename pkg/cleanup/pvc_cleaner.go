@@ -24,14 +24,24 @@ func (c *PVCCleaner) Name() string {
 	return "pvc"
 }
 
-// Execute performs PVC cleanup
-func (c *PVCCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1alpha1.ResourceTypeStats, error) {
+// Enabled reports whether the policy enables PVC cleanup
+func (c *PVCCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.PVC != nil && policy.Spec.Cleanup.PVC.Enabled
+}
+
+// After applies PVC deletions after the pod cleaners, whose pods may still mount the PVCs
+func (c *PVCCleaner) After() []string {
+	return []string{"terminatingpods", "crashlooppods"}
+}
+
+// Plan finds unused PVCs to delete
+func (c *PVCCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
 	log := cleanupCtx.Logger.WithName("pvc-cleaner")
 	stats := &opsv1alpha1.ResourceTypeStats{}
 
 	config := cleanupCtx.Policy.Spec.Cleanup.PVC
 	if config == nil || !config.Enabled {
-		return stats, nil
+		return nil, stats, nil
 	}
 
 	// Parse duration
@@ -39,7 +49,7 @@ func (c *PVCCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1al
 	if err != nil {
 		log.Error(err, "Failed to parse unusedFor duration", "duration", config.UnusedFor)
 		stats.Errors++
-		return stats, err
+		return nil, stats, err
 	}
 
 	cutoffTime := time.Now().Add(-unusedFor)
@@ -49,7 +59,7 @@ func (c *PVCCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1al
 	if err := cleanupCtx.Client.List(ctx, &pvcList); err != nil {
 		log.Error(err, "Failed to list PVCs")
 		stats.Errors++
-		return stats, err
+		return nil, stats, err
 	}
 
 	stats.Scanned = int32(len(pvcList.Items))
@@ -59,7 +69,7 @@ func (c *PVCCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1al
 	if err := cleanupCtx.Client.List(ctx, &podList); err != nil {
 		log.Error(err, "Failed to list pods")
 		stats.Errors++
-		return stats, err
+		return nil, stats, err
 	}
 
 	// Build map of used PVCs
@@ -74,8 +84,10 @@ func (c *PVCCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1al
 	}
 
 	// Process each PVC
-	for _, pvc := range pvcList.Items {
-		if c.shouldSkipPVC(&pvc, config, cleanupCtx) {
+	var candidates []Candidate
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if c.shouldSkipPVC(pvc, config, cleanupCtx) {
 			log.V(1).Info("Skipping PVC", "name", pvc.Name, "namespace", pvc.Namespace)
 			stats.Skipped++
 			continue
@@ -98,30 +110,24 @@ func (c *PVCCleaner) Execute(ctx context.Context, cleanupCtx *Context) (*opsv1al
 		}
 
 		// PVC is unused and old enough to be cleaned
-		if cleanupCtx.DryRun {
-			log.Info("Would delete unused PVC", "name", pvc.Name, "namespace", pvc.Namespace, "age", time.Since(pvc.CreationTimestamp.Time))
-			cleanupCtx.EventRecorder.Event(&pvc, "Normal", "DryRun", "Would delete unused PVC")
-		} else {
-			log.Info("Deleting unused PVC", "name", pvc.Name, "namespace", pvc.Namespace, "age", time.Since(pvc.CreationTimestamp.Time))
-			if err := cleanupCtx.Client.Delete(ctx, &pvc); err != nil {
-				log.Error(err, "Failed to delete PVC", "name", pvc.Name, "namespace", pvc.Namespace)
-				stats.Errors++
-				cleanupCtx.EventRecorder.Event(&pvc, "Warning", "DeleteFailed", "Failed to delete unused PVC")
-				continue
-			}
-			cleanupCtx.EventRecorder.Event(&pvc, "Normal", "Deleted", "Deleted unused PVC")
-		}
-
-		stats.Cleaned++
+		candidates = append(candidates, Candidate{
+			Object:      pvc,
+			Kind:        "PersistentVolumeClaim",
+			Description: "unused PVC",
+			Action:      ActionDelete,
+			Reason:      "not mounted by any pod",
+			Age:         time.Since(pvc.CreationTimestamp.Time),
+			Rule:        "unusedFor=" + config.UnusedFor,
+		})
 	}
 
-	log.Info("PVC cleanup completed",
+	log.Info("PVC planning completed",
 		"scanned", stats.Scanned,
-		"cleaned", stats.Cleaned,
+		"candidates", len(candidates),
 		"skipped", stats.Skipped,
 		"errors", stats.Errors)
 
-	return stats, nil
+	return candidates, stats, nil
 }
 
 // shouldSkipPVC determines if a PVC should be skipped
@@ -0,0 +1,405 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// RBACFixModeManual only reports misconfigurations
+	RBACFixModeManual = "manual"
+
+	// RBACFixModeSuggest reports misconfigurations together with a suggested patch
+	RBACFixModeSuggest = "suggest"
+
+	// RBACFixModeAuto deletes dangling bindings whose subjects no longer exist
+	RBACFixModeAuto = "auto"
+
+	// clusterAdminRole is the built-in superuser ClusterRole
+	clusterAdminRole = "cluster-admin"
+
+	// bootstrappingLabel marks the default RBAC objects created by the API server
+	bootstrappingLabel = "kubernetes.io/bootstrapping"
+
+	// defaultRBACOlderThan is used when RBACCheckConfig.OlderThan is not set
+	defaultRBACOlderThan = time.Hour
+)
+
+// rbacBinding is a RoleBinding or ClusterRoleBinding with its common fields
+type rbacBinding struct {
+	Object   client.Object
+	Kind     string
+	RoleRef  rbacv1.RoleRef
+	Subjects []rbacv1.Subject
+}
+
+// RBACChecker validates RBAC configurations
+type RBACChecker struct{}
+
+// NewRBACChecker creates a new RBAC checker
+func NewRBACChecker() *RBACChecker {
+	return &RBACChecker{}
+}
+
+// Name returns the name of the cleaner
+func (c *RBACChecker) Name() string {
+	return "rbaccheck"
+}
+
+// Enabled reports whether the policy enables the RBAC check
+func (c *RBACChecker) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.RBACCheck != nil && policy.Spec.Cleanup.RBACCheck.Enabled
+}
+
+// Plan validates RBAC objects and plans deleting dangling bindings in auto fix mode
+func (c *RBACChecker) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("rbac-checker")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.RBACCheck
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	fixMode := config.FixMode
+	if fixMode == "" {
+		fixMode = RBACFixModeManual
+	}
+
+	// Parse duration
+	olderThan := defaultRBACOlderThan
+	if config.OlderThan != "" {
+		parsed, err := time.ParseDuration(config.OlderThan)
+		if err != nil {
+			log.Error(err, "Failed to parse olderThan duration", "duration", config.OlderThan)
+			stats.Errors++
+			return nil, stats, err
+		}
+		olderThan = parsed
+	}
+
+	// Get all RBAC objects and ServiceAccounts
+	var roleList rbacv1.RoleList
+	if err := cleanupCtx.Client.List(ctx, &roleList); err != nil {
+		log.Error(err, "Failed to list Roles")
+		stats.Errors++
+		return nil, stats, err
+	}
+	var clusterRoleList rbacv1.ClusterRoleList
+	if err := cleanupCtx.Client.List(ctx, &clusterRoleList); err != nil {
+		log.Error(err, "Failed to list ClusterRoles")
+		stats.Errors++
+		return nil, stats, err
+	}
+	var roleBindingList rbacv1.RoleBindingList
+	if err := cleanupCtx.Client.List(ctx, &roleBindingList); err != nil {
+		log.Error(err, "Failed to list RoleBindings")
+		stats.Errors++
+		return nil, stats, err
+	}
+	var clusterRoleBindingList rbacv1.ClusterRoleBindingList
+	if err := cleanupCtx.Client.List(ctx, &clusterRoleBindingList); err != nil {
+		log.Error(err, "Failed to list ClusterRoleBindings")
+		stats.Errors++
+		return nil, stats, err
+	}
+	var serviceAccountList corev1.ServiceAccountList
+	if err := cleanupCtx.Client.List(ctx, &serviceAccountList); err != nil {
+		log.Error(err, "Failed to list ServiceAccounts")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	roles := make(map[string]bool)
+	for _, role := range roleList.Items {
+		roles[role.Namespace+"/"+role.Name] = true
+	}
+	clusterRoles := make(map[string]bool)
+	for _, clusterRole := range clusterRoleList.Items {
+		clusterRoles[clusterRole.Name] = true
+	}
+	serviceAccounts := make(map[string]bool)
+	for _, sa := range serviceAccountList.Items {
+		serviceAccounts[sa.Namespace+"/"+sa.Name] = true
+	}
+
+	var bindings []rbacBinding
+	for i := range roleBindingList.Items {
+		rb := &roleBindingList.Items[i]
+		bindings = append(bindings, rbacBinding{Object: rb, Kind: "RoleBinding", RoleRef: rb.RoleRef, Subjects: rb.Subjects})
+	}
+	for i := range clusterRoleBindingList.Items {
+		crb := &clusterRoleBindingList.Items[i]
+		bindings = append(bindings, rbacBinding{Object: crb, Kind: "ClusterRoleBinding", RoleRef: crb.RoleRef, Subjects: crb.Subjects})
+	}
+
+	stats.Scanned = int32(len(bindings) + len(roleList.Items) + len(clusterRoleList.Items))
+
+	// Check bindings
+	var candidates []Candidate
+	for _, binding := range bindings {
+		obj := binding.Object
+		if c.shouldSkip(obj, cleanupCtx) {
+			stats.Skipped++
+			continue
+		}
+
+		// Bindings to a missing Role or ClusterRole
+		if !c.roleExists(binding, roles, clusterRoles) {
+			message := fmt.Sprintf("%s references missing %s %s", binding.Kind, binding.RoleRef.Kind, binding.RoleRef.Name)
+			c.report(cleanupCtx, obj, binding.Kind, "MissingRole", message, fixMode, c.deleteSuggestion(binding))
+			stats.Skipped++
+		}
+
+		// Subjects that point at missing ServiceAccounts
+		missing, remaining := c.splitMissingSubjects(binding, serviceAccounts)
+		if len(missing) > 0 {
+			message := fmt.Sprintf("%s references missing ServiceAccounts %s", binding.Kind, strings.Join(missing, ", "))
+
+			// Only bindings whose subjects are all gone are safe to delete automatically. Younger
+			// bindings are reported, their ServiceAccounts may be created after them by GitOps or Helm.
+			age := time.Since(obj.GetCreationTimestamp().Time)
+			if fixMode == RBACFixModeAuto && len(remaining) == 0 && age >= olderThan {
+				candidates = append(candidates, Candidate{
+					Object:      obj,
+					Kind:        binding.Kind,
+					Description: "dangling binding",
+					Action:      ActionDelete,
+					Reason:      message,
+					Age:         age,
+					Rule:        "fixMode=" + fixMode + ",olderThan=" + olderThan.String(),
+				})
+				continue
+			}
+
+			suggestion := c.deleteSuggestion(binding)
+			if len(remaining) > 0 {
+				suggestion = c.subjectsSuggestion(binding, remaining)
+			}
+			c.report(cleanupCtx, obj, binding.Kind, "DanglingSubjects", message, fixMode, suggestion)
+			stats.Skipped++
+		}
+
+		// cluster-admin granted to non-system subjects
+		if binding.RoleRef.Kind == "ClusterRole" && binding.RoleRef.Name == clusterAdminRole {
+			var systemSubjects, userSubjects []rbacv1.Subject
+			for _, subject := range binding.Subjects {
+				if isSystemSubject(subject) {
+					systemSubjects = append(systemSubjects, subject)
+				} else {
+					userSubjects = append(userSubjects, subject)
+				}
+			}
+			if len(userSubjects) > 0 {
+				message := fmt.Sprintf("%s grants cluster-admin to non-system subjects %s", binding.Kind, describeSubjects(userSubjects))
+				suggestion := c.deleteSuggestion(binding)
+				if len(systemSubjects) > 0 {
+					suggestion = c.subjectsSuggestion(binding, systemSubjects)
+				}
+				c.report(cleanupCtx, obj, binding.Kind, "ClusterAdminGranted", message, fixMode, suggestion)
+				stats.Skipped++
+			}
+		}
+	}
+
+	// Check Roles and ClusterRoles for wildcards
+	for i := range roleList.Items {
+		role := &roleList.Items[i]
+		if c.shouldSkip(role, cleanupCtx) {
+			continue
+		}
+		if rules := wildcardRules(role.Rules); len(rules) > 0 {
+			c.report(cleanupCtx, role, "Role", "WildcardRules", describeWildcards("Role", rules), fixMode, "")
+			stats.Skipped++
+		}
+	}
+	for i := range clusterRoleList.Items {
+		clusterRole := &clusterRoleList.Items[i]
+		if c.shouldSkip(clusterRole, cleanupCtx) || clusterRole.Name == clusterAdminRole {
+			continue
+		}
+		if rules := wildcardRules(clusterRole.Rules); len(rules) > 0 {
+			c.report(cleanupCtx, clusterRole, "ClusterRole", "WildcardRules", describeWildcards("ClusterRole", rules), fixMode, "")
+			stats.Skipped++
+		}
+	}
+
+	log.Info("RBAC planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// shouldSkip determines if an RBAC object should be skipped
+func (c *RBACChecker) shouldSkip(obj client.Object, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if obj.GetNamespace() != "" && IsNamespaceIgnored(obj.GetNamespace(), cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if object has protected labels
+	if IsProtected(obj.GetLabels(), cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	// Skip if object is in terminating state
+	if obj.GetDeletionTimestamp() != nil {
+		return true
+	}
+
+	// Skip the default RBAC objects maintained by the API server
+	if strings.HasPrefix(obj.GetName(), "system:") || obj.GetLabels()[bootstrappingLabel] == "rbac-defaults" {
+		return true
+	}
+
+	return false
+}
+
+// roleExists checks if the Role or ClusterRole referenced by a binding exists
+func (c *RBACChecker) roleExists(binding rbacBinding, roles, clusterRoles map[string]bool) bool {
+	switch binding.RoleRef.Kind {
+	case "Role":
+		return roles[binding.Object.GetNamespace()+"/"+binding.RoleRef.Name]
+	case "ClusterRole":
+		return clusterRoles[binding.RoleRef.Name]
+	}
+	return true
+}
+
+// splitMissingSubjects separates ServiceAccount subjects that no longer exist from the remaining subjects
+func (c *RBACChecker) splitMissingSubjects(binding rbacBinding, serviceAccounts map[string]bool) ([]string, []rbacv1.Subject) {
+	var missing []string
+	var remaining []rbacv1.Subject
+
+	for _, subject := range binding.Subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			namespace := subject.Namespace
+			if namespace == "" {
+				namespace = binding.Object.GetNamespace()
+			}
+			if !serviceAccounts[namespace+"/"+subject.Name] {
+				missing = append(missing, namespace+"/"+subject.Name)
+				continue
+			}
+		}
+		remaining = append(remaining, subject)
+	}
+
+	return missing, remaining
+}
+
+// report emits a Warning event and records a finding, attaching the suggestion in suggest mode
+func (c *RBACChecker) report(cleanupCtx *Context, obj client.Object, kind, reason, message, fixMode, suggestion string) {
+	cleanupCtx.Logger.WithName("rbac-checker").Info("RBAC misconfiguration found",
+		"kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace(), "reason", reason)
+	cleanupCtx.EventRecorder.Event(obj, "Warning", reason, message)
+
+	finding := opsv1alpha1.Finding{
+		Cleaner:   c.Name(),
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Reason:    reason,
+		Message:   message,
+	}
+	if fixMode != RBACFixModeManual {
+		finding.Suggestion = suggestion
+	}
+	cleanupCtx.Report(finding)
+}
+
+// subjectsSuggestion renders a merge patch that keeps only the given subjects
+func (c *RBACChecker) subjectsSuggestion(binding rbacBinding, subjects []rbacv1.Subject) string {
+	patch := map[string]interface{}{
+		"apiVersion": rbacv1.SchemeGroupVersion.String(),
+		"kind":       binding.Kind,
+		"metadata":   bindingMetadata(binding),
+		"subjects":   subjects,
+	}
+	data, err := yaml.Marshal(patch)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// deleteSuggestion renders the kubectl command that removes a binding
+func (c *RBACChecker) deleteSuggestion(binding rbacBinding) string {
+	obj := binding.Object
+	if obj.GetNamespace() == "" {
+		return fmt.Sprintf("kubectl delete %s %s", strings.ToLower(binding.Kind), obj.GetName())
+	}
+	return fmt.Sprintf("kubectl delete %s %s -n %s", strings.ToLower(binding.Kind), obj.GetName(), obj.GetNamespace())
+}
+
+// bindingMetadata returns the identifying metadata of a binding for a patch
+func bindingMetadata(binding rbacBinding) map[string]string {
+	metadata := map[string]string{"name": binding.Object.GetName()}
+	if binding.Object.GetNamespace() != "" {
+		metadata["namespace"] = binding.Object.GetNamespace()
+	}
+	return metadata
+}
+
+// isSystemSubject checks if a subject belongs to the Kubernetes control plane
+func isSystemSubject(subject rbacv1.Subject) bool {
+	if strings.HasPrefix(subject.Name, "system:") {
+		return true
+	}
+	return subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == "kube-system"
+}
+
+// describeSubjects renders subjects as Kind/namespace/name
+func describeSubjects(subjects []rbacv1.Subject) string {
+	parts := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Namespace != "" {
+			parts = append(parts, fmt.Sprintf("%s %s/%s", subject.Kind, subject.Namespace, subject.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %s", subject.Kind, subject.Name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// wildcardRules returns the policy rules that use a wildcard verb or resource
+func wildcardRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	var result []rbacv1.PolicyRule
+	for _, rule := range rules {
+		if containsWildcard(rule.Verbs) || containsWildcard(rule.Resources) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// containsWildcard checks if a list contains the "*" wildcard
+func containsWildcard(values []string) bool {
+	for _, value := range values {
+		if value == rbacv1.VerbAll {
+			return true
+		}
+	}
+	return false
+}
+
+// describeWildcards renders wildcard rules as a one-line summary
+func describeWildcards(kind string, rules []rbacv1.PolicyRule) string {
+	parts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		parts = append(parts, fmt.Sprintf("apiGroups=%v resources=%v verbs=%v", rule.APIGroups, rule.Resources, rule.Verbs))
+	}
+	return fmt.Sprintf("%s uses wildcard rules: %s", kind, strings.Join(parts, "; "))
+}
@@ -0,0 +1,263 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// serviceAccountSubject returns a ServiceAccount subject
+func serviceAccountSubject(namespace, name string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: name}
+}
+
+func TestRBACCheckerPlan(t *testing.T) {
+	tests := []struct {
+		name           string
+		fixMode        string
+		olderThan      string
+		age            time.Duration
+		subjects       []rbacv1.Subject
+		wantDeleted    bool
+		wantReason     string
+		wantSuggestion string
+	}{
+		{
+			name:        "dangling binding deleted in auto mode",
+			fixMode:     RBACFixModeAuto,
+			age:         2 * time.Hour,
+			subjects:    []rbacv1.Subject{serviceAccountSubject("apps", "gone")},
+			wantDeleted: true,
+		},
+		{
+			name:           "young dangling binding reported in auto mode",
+			fixMode:        RBACFixModeAuto,
+			age:            10 * time.Minute,
+			subjects:       []rbacv1.Subject{serviceAccountSubject("apps", "gone")},
+			wantReason:     "DanglingSubjects",
+			wantSuggestion: "kubectl delete rolebinding reader -n apps",
+		},
+		{
+			name:        "configured minimum age",
+			fixMode:     RBACFixModeAuto,
+			olderThan:   "5m",
+			age:         10 * time.Minute,
+			subjects:    []rbacv1.Subject{serviceAccountSubject("apps", "gone")},
+			wantDeleted: true,
+		},
+		{
+			name:           "partially dangling binding reported in auto mode",
+			fixMode:        RBACFixModeAuto,
+			age:            2 * time.Hour,
+			subjects:       []rbacv1.Subject{serviceAccountSubject("apps", "gone"), serviceAccountSubject("apps", "web")},
+			wantReason:     "DanglingSubjects",
+			wantSuggestion: "apiVersion: rbac.authorization.k8s.io/v1\nkind: RoleBinding\nmetadata:\n  name: reader\n  namespace: apps\nsubjects:\n- kind: ServiceAccount\n  name: web\n  namespace: apps\n",
+		},
+		{
+			name:           "suggest mode",
+			fixMode:        RBACFixModeSuggest,
+			age:            2 * time.Hour,
+			subjects:       []rbacv1.Subject{serviceAccountSubject("apps", "gone")},
+			wantReason:     "DanglingSubjects",
+			wantSuggestion: "kubectl delete rolebinding reader -n apps",
+		},
+		{
+			name:       "manual mode",
+			fixMode:    RBACFixModeManual,
+			age:        2 * time.Hour,
+			subjects:   []rbacv1.Subject{serviceAccountSubject("apps", "gone")},
+			wantReason: "DanglingSubjects",
+		},
+		{
+			name:     "existing ServiceAccount in the binding namespace",
+			fixMode:  RBACFixModeAuto,
+			age:      2 * time.Hour,
+			subjects: []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "web"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "apps", CreationTimestamp: metav1.NewTime(time.Now().Add(-tt.age))},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "reader"},
+				Subjects:   tt.subjects,
+			}
+			role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "apps"}}
+			serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"}}
+
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Cleanup.RBACCheck = &opsv1alpha1.RBACCheckConfig{Enabled: true, FixMode: tt.fixMode, OlderThan: tt.olderThan}
+			cleanupCtx := newTestContext(policy, binding, role, serviceAccount)
+
+			candidates, _, err := NewRBACChecker().Plan(context.Background(), cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if deleted := len(candidates) == 1 && candidates[0].Action == ActionDelete; deleted != tt.wantDeleted || len(candidates) > 1 {
+				t.Errorf("got %d candidates, want deletion %v", len(candidates), tt.wantDeleted)
+			}
+
+			findings := cleanupCtx.Findings()
+			if tt.wantReason == "" {
+				if len(findings) > 0 {
+					t.Errorf("unexpected findings %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 || findings[0].Reason != tt.wantReason {
+				t.Fatalf("got findings %+v, want one with reason %s", findings, tt.wantReason)
+			}
+			if findings[0].Suggestion != tt.wantSuggestion {
+				t.Errorf("got suggestion %q, want %q", findings[0].Suggestion, tt.wantSuggestion)
+			}
+		})
+	}
+}
+
+func TestSplitMissingSubjects(t *testing.T) {
+	serviceAccounts := map[string]bool{"apps/web": true, "ci/builder": true}
+
+	tests := []struct {
+		name          string
+		subjects      []rbacv1.Subject
+		wantMissing   []string
+		wantRemaining []rbacv1.Subject
+	}{
+		{name: "no subjects"},
+		{
+			name:          "existing ServiceAccounts",
+			subjects:      []rbacv1.Subject{serviceAccountSubject("apps", "web"), serviceAccountSubject("ci", "builder")},
+			wantRemaining: []rbacv1.Subject{serviceAccountSubject("apps", "web"), serviceAccountSubject("ci", "builder")},
+		},
+		{
+			name:        "namespace defaults to the binding namespace",
+			subjects:    []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "gone"}},
+			wantMissing: []string{"apps/gone"},
+		},
+		{
+			name: "users and groups are never missing",
+			subjects: []rbacv1.Subject{
+				{Kind: rbacv1.UserKind, Name: "jane"},
+				serviceAccountSubject("ci", "gone"),
+				{Kind: rbacv1.GroupKind, Name: "admins"},
+			},
+			wantMissing:   []string{"ci/gone"},
+			wantRemaining: []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "jane"}, {Kind: rbacv1.GroupKind, Name: "admins"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := rbacBinding{
+				Object:   &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "binding", Namespace: "apps"}},
+				Kind:     "RoleBinding",
+				Subjects: tt.subjects,
+			}
+			missing, remaining := NewRBACChecker().splitMissingSubjects(binding, serviceAccounts)
+			if !reflect.DeepEqual(missing, tt.wantMissing) {
+				t.Errorf("got missing %v, want %v", missing, tt.wantMissing)
+			}
+			if !reflect.DeepEqual(remaining, tt.wantRemaining) {
+				t.Errorf("got remaining %v, want %v", remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestIsSystemSubject(t *testing.T) {
+	tests := []struct {
+		subject rbacv1.Subject
+		want    bool
+	}{
+		{subject: rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:masters"}, want: true},
+		{subject: rbacv1.Subject{Kind: rbacv1.UserKind, Name: "system:kube-controller-manager"}, want: true},
+		{subject: serviceAccountSubject("kube-system", "coredns"), want: true},
+		{subject: serviceAccountSubject("apps", "web")},
+		{subject: rbacv1.Subject{Kind: rbacv1.UserKind, Name: "jane"}},
+		{subject: rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "kube-system"}},
+	}
+
+	for _, tt := range tests {
+		if got := isSystemSubject(tt.subject); got != tt.want {
+			t.Errorf("isSystemSubject(%+v) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestWildcardRules(t *testing.T) {
+	readPods := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}}
+	allVerbs := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}}
+	allResources := rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"*"}, Verbs: []string{"get"}}
+	allGroups := rbacv1.PolicyRule{APIGroups: []string{"*"}, Resources: []string{"pods"}, Verbs: []string{"get"}}
+
+	tests := []struct {
+		name  string
+		rules []rbacv1.PolicyRule
+		want  []rbacv1.PolicyRule
+	}{
+		{name: "no wildcards", rules: []rbacv1.PolicyRule{readPods}},
+		{name: "wildcard verb", rules: []rbacv1.PolicyRule{readPods, allVerbs}, want: []rbacv1.PolicyRule{allVerbs}},
+		{name: "wildcard resource", rules: []rbacv1.PolicyRule{allResources, readPods}, want: []rbacv1.PolicyRule{allResources}},
+		{name: "wildcard API group only", rules: []rbacv1.PolicyRule{allGroups}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wildcardRules(tt.rules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubjectsSuggestion(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding rbacBinding
+		want    map[string]interface{}
+	}{
+		{
+			name:    "RoleBinding",
+			binding: rbacBinding{Object: &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "apps"}}, Kind: "RoleBinding"},
+			want: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "RoleBinding",
+				"metadata":   map[string]interface{}{"name": "reader", "namespace": "apps"},
+				"subjects":   []interface{}{map[string]interface{}{"kind": "ServiceAccount", "name": "web", "namespace": "apps"}},
+			},
+		},
+		{
+			name:    "ClusterRoleBinding",
+			binding: rbacBinding{Object: &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "admins"}}, Kind: "ClusterRoleBinding"},
+			want: map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRoleBinding",
+				"metadata":   map[string]interface{}{"name": "admins"},
+				"subjects":   []interface{}{map[string]interface{}{"kind": "ServiceAccount", "name": "web", "namespace": "apps"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestion := NewRBACChecker().subjectsSuggestion(tt.binding, []rbacv1.Subject{serviceAccountSubject("apps", "web")})
+
+			var got map[string]interface{}
+			if err := yaml.Unmarshal([]byte(suggestion), &got); err != nil {
+				t.Fatalf("suggestion is not valid YAML: %v\n%s", err, suggestion)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got suggestion\n%s\nwant %v", suggestion, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// helmManagedByLabel is set to Helm on the objects rendered by a chart
+	helmManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// helmReleaseNameAnnotation names the release an object rendered by a chart belongs to
+	helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+)
+
+// gatewayListKinds are the Gateway API versions Gateways are listed from, in order of preference
+var gatewayListKinds = []schema.GroupVersionKind{
+	{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "GatewayList"},
+	{Group: "gateway.networking.k8s.io", Version: "v1beta1", Kind: "GatewayList"},
+}
+
+// namespacedPodSpec pairs a pod spec with the namespace it lives in
+type namespacedPodSpec struct {
+	Namespace string
+	Spec      *corev1.PodSpec
+}
+
+// listPodSpecs collects the pod specs of all Pods and of every workload pod template,
+// so that objects referenced only by a scaled-down or not-yet-run workload are still seen as used
+func listPodSpecs(ctx context.Context, c client.Client) ([]namespacedPodSpec, error) {
+	var specs []namespacedPodSpec
+
+	var podList corev1.PodList
+	if err := c.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range podList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: podList.Items[i].Namespace, Spec: &podList.Items[i].Spec})
+	}
+
+	var deploymentList appsv1.DeploymentList
+	if err := c.List(ctx, &deploymentList); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deploymentList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: deploymentList.Items[i].Namespace, Spec: &deploymentList.Items[i].Spec.Template.Spec})
+	}
+
+	// ReplicaSets are included so that older revisions kept for rollback remain valid
+	var replicaSetList appsv1.ReplicaSetList
+	if err := c.List(ctx, &replicaSetList); err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for i := range replicaSetList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: replicaSetList.Items[i].Namespace, Spec: &replicaSetList.Items[i].Spec.Template.Spec})
+	}
+
+	var statefulSetList appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSetList); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSetList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: statefulSetList.Items[i].Namespace, Spec: &statefulSetList.Items[i].Spec.Template.Spec})
+	}
+
+	var daemonSetList appsv1.DaemonSetList
+	if err := c.List(ctx, &daemonSetList); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSetList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: daemonSetList.Items[i].Namespace, Spec: &daemonSetList.Items[i].Spec.Template.Spec})
+	}
+
+	var jobList batchv1.JobList
+	if err := c.List(ctx, &jobList); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: jobList.Items[i].Namespace, Spec: &jobList.Items[i].Spec.Template.Spec})
+	}
+
+	var cronJobList batchv1.CronJobList
+	if err := c.List(ctx, &cronJobList); err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobList.Items {
+		specs = append(specs, namespacedPodSpec{Namespace: cronJobList.Items[i].Namespace, Spec: &cronJobList.Items[i].Spec.JobTemplate.Spec.Template.Spec})
+	}
+
+	return specs, nil
+}
+
+// configMapReferences builds the set of "namespace/name" keys of ConfigMaps referenced by the given pod specs
+func configMapReferences(specs []namespacedPodSpec) map[string]bool {
+	refs := make(map[string]bool)
+	for _, s := range specs {
+		for _, name := range podSpecConfigMapNames(s.Spec) {
+			refs[s.Namespace+"/"+name] = true
+		}
+	}
+	return refs
+}
+
+// podSpecConfigMapNames returns the names of all ConfigMaps a pod spec refers to
+func podSpecConfigMapNames(spec *corev1.PodSpec) []string {
+	var names []string
+
+	for _, volume := range spec.Volumes {
+		if volume.ConfigMap != nil {
+			names = append(names, volume.ConfigMap.Name)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					names = append(names, source.ConfigMap.Name)
+				}
+			}
+		}
+	}
+
+	visitEnv := func(env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+		for _, e := range envFrom {
+			if e.ConfigMapRef != nil {
+				names = append(names, e.ConfigMapRef.Name)
+			}
+		}
+		for _, e := range env {
+			if e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil {
+				names = append(names, e.ValueFrom.ConfigMapKeyRef.Name)
+			}
+		}
+	}
+
+	for _, container := range spec.InitContainers {
+		visitEnv(container.Env, container.EnvFrom)
+	}
+	for _, container := range spec.Containers {
+		visitEnv(container.Env, container.EnvFrom)
+	}
+	for _, container := range spec.EphemeralContainers {
+		visitEnv(container.Env, container.EnvFrom)
+	}
+
+	return names
+}
+
+// secretReferences builds the set of "namespace/name" keys of Secrets referenced by the given pod specs
+func secretReferences(specs []namespacedPodSpec) map[string]bool {
+	refs := make(map[string]bool)
+	for _, s := range specs {
+		for _, name := range podSpecSecretNames(s.Spec) {
+			refs[s.Namespace+"/"+name] = true
+		}
+	}
+	return refs
+}
+
+// podSpecSecretNames returns the names of all Secrets a pod spec refers to
+func podSpecSecretNames(spec *corev1.PodSpec) []string {
+	var names []string
+
+	for _, pullSecret := range spec.ImagePullSecrets {
+		names = append(names, pullSecret.Name)
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			names = append(names, volume.Secret.SecretName)
+		}
+		if volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil {
+			names = append(names, volume.CSI.NodePublishSecretRef.Name)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					names = append(names, source.Secret.Name)
+				}
+			}
+		}
+	}
+
+	visitEnv := func(env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+		for _, e := range envFrom {
+			if e.SecretRef != nil {
+				names = append(names, e.SecretRef.Name)
+			}
+		}
+		for _, e := range env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+				names = append(names, e.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	for _, container := range spec.InitContainers {
+		visitEnv(container.Env, container.EnvFrom)
+	}
+	for _, container := range spec.Containers {
+		visitEnv(container.Env, container.EnvFrom)
+	}
+	for _, container := range spec.EphemeralContainers {
+		visitEnv(container.Env, container.EnvFrom)
+	}
+
+	return names
+}
+
+// helmManaged reports whether the object was rendered by a Helm chart. Such objects belong to
+// their release even when no workload uses them, e.g. Secrets read by operators or webhooks.
+func helmManaged(obj metav1.Object) bool {
+	if obj.GetLabels()[helmManagedByLabel] == "Helm" {
+		return true
+	}
+	_, exists := obj.GetAnnotations()[helmReleaseNameAnnotation]
+	return exists
+}
+
+// gatewayCertificateReferences builds the set of "namespace/name" keys of Secrets referenced by the
+// TLS certificateRefs of Gateway listeners. Without the Gateway API installed the set is empty.
+func gatewayCertificateReferences(ctx context.Context, c client.Client) (map[string]bool, error) {
+	refs := make(map[string]bool)
+
+	for _, gvk := range gatewayListKinds {
+		gatewayList := &unstructured.UnstructuredList{}
+		gatewayList.SetGroupVersionKind(gvk)
+		if err := c.List(ctx, gatewayList); err != nil {
+			// Gateway API is optional; try the next version or skip it when not installed
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list gateways: %w", err)
+		}
+		for i := range gatewayList.Items {
+			for _, key := range gatewayCertificateSecrets(&gatewayList.Items[i]) {
+				refs[key] = true
+			}
+		}
+		break
+	}
+
+	return refs, nil
+}
+
+// gatewayCertificateSecrets extracts the "namespace/name" keys of Secrets referenced by the listeners of a Gateway
+func gatewayCertificateSecrets(gateway *unstructured.Unstructured) []string {
+	var keys []string
+
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, listener := range listeners {
+		listenerMap, ok := listener.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		certificateRefs, _, _ := unstructured.NestedSlice(listenerMap, "tls", "certificateRefs")
+		for _, ref := range certificateRefs {
+			refMap, ok := ref.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, _, _ := unstructured.NestedString(refMap, "group")
+			kind, _, _ := unstructured.NestedString(refMap, "kind")
+			if (group != "" && group != "core") || (kind != "" && kind != "Secret") {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(refMap, "name")
+			namespace, _, _ := unstructured.NestedString(refMap, "namespace")
+			if namespace == "" {
+				namespace = gateway.GetNamespace()
+			}
+			if name != "" {
+				keys = append(keys, namespace+"/"+name)
+			}
+		}
+	}
+
+	return keys
+}
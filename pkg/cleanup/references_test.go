@@ -0,0 +1,208 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestGatewayCertificateSecrets(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []interface{}
+		want      []string
+	}{
+		{name: "no listeners"},
+		{
+			name:      "listener without TLS",
+			listeners: []interface{}{map[string]interface{}{"name": "http", "port": int64(80)}},
+		},
+		{
+			name: "references in the Gateway namespace",
+			listeners: []interface{}{
+				map[string]interface{}{"tls": map[string]interface{}{"certificateRefs": []interface{}{
+					map[string]interface{}{"name": "web"},
+					map[string]interface{}{"group": "", "kind": "Secret", "name": "api"},
+				}}},
+				map[string]interface{}{"tls": map[string]interface{}{"certificateRefs": []interface{}{
+					map[string]interface{}{"group": "core", "name": "admin"},
+				}}},
+			},
+			want: []string{"infra/web", "infra/api", "infra/admin"},
+		},
+		{
+			name: "reference to another namespace",
+			listeners: []interface{}{map[string]interface{}{"tls": map[string]interface{}{"certificateRefs": []interface{}{
+				map[string]interface{}{"name": "shared", "namespace": "certs"},
+			}}}},
+			want: []string{"certs/shared"},
+		},
+		{
+			name: "references to other kinds ignored",
+			listeners: []interface{}{map[string]interface{}{"tls": map[string]interface{}{"certificateRefs": []interface{}{
+				map[string]interface{}{"group": "example.com", "kind": "Certificate", "name": "cert"},
+				map[string]interface{}{"kind": "ConfigMap", "name": "bundle"},
+				map[string]interface{}{"kind": "Secret"},
+			}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+			gateway.SetNamespace("infra")
+			if tt.listeners != nil {
+				if err := unstructured.SetNestedSlice(gateway.Object, tt.listeners, "spec", "listeners"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := gatewayCertificateSecrets(gateway); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodSpecConfigMapNames(t *testing.T) {
+	configMapRef := func(name string) corev1.LocalObjectReference {
+		return corev1.LocalObjectReference{Name: name}
+	}
+
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want []string
+	}{
+		{name: "no references"},
+		{
+			name: "volume",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: configMapRef("app-config")}}},
+				{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			}},
+			want: []string{"app-config"},
+		},
+		{
+			name: "projected volume",
+			spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "bundle", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: configMapRef("ca-bundle")}},
+					{Secret: &corev1.SecretProjection{LocalObjectReference: configMapRef("tls")}},
+					{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token"}},
+				},
+			}}}}},
+			want: []string{"ca-bundle"},
+		},
+		{
+			name: "envFrom and valueFrom",
+			spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				EnvFrom: []corev1.EnvFromSource{
+					{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: configMapRef("app-env")}},
+					{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: configMapRef("app-secrets")}},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "LEVEL", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: configMapRef("logging"), Key: "level"}}},
+					{Name: "MODE", Value: "production"},
+					{Name: "POD", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+				},
+			}}},
+			want: []string{"app-env", "logging"},
+		},
+		{
+			name: "init and ephemeral containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{
+					Name:    "migrate",
+					EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: configMapRef("migrations")}}},
+				}},
+				EphemeralContainers: []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+					Name:    "debug",
+					EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: configMapRef("debug-env")}}},
+				}}},
+			},
+			want: []string{"migrations", "debug-env"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podSpecConfigMapNames(&tt.spec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigMapReferences(t *testing.T) {
+	template := func(configMap string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "config",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}}},
+		}}}}
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    map[string]bool
+	}{
+		{
+			name:    "Pod",
+			objects: []client.Object{&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"}, Spec: template("web-config").Spec}},
+			want:    map[string]bool{"apps/web-config": true},
+		},
+		{
+			name: "scaled-down Deployment",
+			objects: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "apps"},
+				Spec:       appsv1.DeploymentSpec{Template: template("api-config")},
+			}},
+			want: map[string]bool{"apps/api-config": true},
+		},
+		{
+			name: "CronJob that has not run yet",
+			objects: []client.Object{&batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "batch"},
+				Spec:       batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template("report-config")}}},
+			}},
+			want: map[string]bool{"batch/report-config": true},
+		},
+		{
+			name: "same name in different namespaces",
+			objects: []client.Object{
+				&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"}, Spec: appsv1.StatefulSetSpec{Template: template("settings")}},
+				&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "team-b"}, Spec: appsv1.DaemonSetSpec{Template: template("settings")}},
+			},
+			want: map[string]bool{"team-a/settings": true, "team-b/settings": true},
+		},
+		{
+			name: "no workloads",
+			want: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupCtx := newTestContext(&opsv1alpha1.JanitorPolicy{}, tt.objects...)
+
+			specs, err := listPodSpecs(context.Background(), cleanupCtx.Client)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := configMapReferences(specs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package cleanup
+
+import (
+	"fmt"
+	"sync"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   []Cleaner
+)
+
+// ConfigDecoder is implemented by cleaners that are configured through spec.cleanup.extensions.
+// The engine decodes the raw JSON stored under the cleaner name before each run and makes
+// the result available through Context.CleanerConfig.
+type ConfigDecoder interface {
+	DecodeConfig(raw []byte) (interface{}, error)
+}
+
+// OrderedCleaner is implemented by cleaners whose changes must be applied after those of other
+// cleaners, for example PVCs after the pods that mount them. Changes of cleaners without ordering
+// constraints between them are applied concurrently.
+type OrderedCleaner interface {
+	// After returns the names of the cleaners whose changes are applied first
+	After() []string
+}
+
+func init() {
+	// Built-in cleaners, in execution order
+	for _, cleaner := range []Cleaner{
+		NewPVCCleaner(),
+		NewJobsCleaner(),
+		NewConfigMapsCleaner(),
+		NewSecretsCleaner(),
+		NewServicesCleaner(),
+		NewTLSSecretsCleaner(),
+		NewTerminatingPodsCleaner(),
+		NewCrashLoopPodsCleaner(),
+		NewResourceGapsChecker(),
+		NewRBACChecker(),
+		NewStaleHelmReleasesCleaner(),
+	} {
+		Register(cleaner)
+	}
+}
+
+// Register adds a cleaner to the set used by engines created afterwards. Cleaners run in
+// registration order, after the built-in cleaners. Out-of-tree cleaners typically call
+// Register from an init function of a package imported by a custom cmd/main.go.
+// Register panics if a cleaner with the same name is already registered.
+func Register(cleaner Cleaner) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, registered := range registry {
+		if registered.Name() == cleaner.Name() {
+			panic(fmt.Sprintf("cleanup: cleaner %q registered twice", cleaner.Name()))
+		}
+	}
+	registry = append(registry, cleaner)
+}
+
+// Registered returns the registered cleaners in execution order
+func Registered() []Cleaner {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]Cleaner(nil), registry...)
+}
+
+// HasExtension reports whether the policy configures the named cleaner in spec.cleanup.extensions.
+// Out-of-tree cleaners can use it to implement Enabled.
+func HasExtension(policy *opsv1alpha1.JanitorPolicy, name string) bool {
+	_, exists := policy.Spec.Cleanup.Extensions[name]
+	return exists
+}
+
+// decodeConfigs decodes the extension configuration of every cleaner implementing ConfigDecoder
+func decodeConfigs(cleaners []Cleaner, policy *opsv1alpha1.JanitorPolicy) (map[string]interface{}, map[string]error) {
+	configs := make(map[string]interface{})
+	errs := make(map[string]error)
+
+	for _, cleaner := range cleaners {
+		decoder, ok := cleaner.(ConfigDecoder)
+		if !ok {
+			continue
+		}
+		raw, exists := policy.Spec.Cleanup.Extensions[cleaner.Name()]
+		if !exists {
+			continue
+		}
+
+		config, err := decoder.DecodeConfig(raw.Raw)
+		if err != nil {
+			errs[cleaner.Name()] = fmt.Errorf("invalid configuration for cleaner %s: %w", cleaner.Name(), err)
+			continue
+		}
+		configs[cleaner.Name()] = config
+	}
+
+	return configs, errs
+}
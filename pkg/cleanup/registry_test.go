@@ -0,0 +1,199 @@
+package cleanup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testExtensionConfig is the configuration of testDecodingCleaner
+type testExtensionConfig struct {
+	Threshold int `json:"threshold"`
+}
+
+// testDecodingCleaner is an out-of-tree cleaner configured through spec.cleanup.extensions.
+// It records the configuration it was planned with.
+type testDecodingCleaner struct {
+	testCleaner
+	planned bool
+	config  interface{}
+}
+
+func (c *testDecodingCleaner) DecodeConfig(raw []byte) (interface{}, error) {
+	config := &testExtensionConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *testDecodingCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	c.planned = true
+	c.config = cleanupCtx.CleanerConfig(c.Name())
+	return c.testCleaner.Plan(ctx, cleanupCtx)
+}
+
+// restoreRegistry resets the registry to its current content when the test ends
+func restoreRegistry(t *testing.T) {
+	saved := Registered()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		registry = saved
+	})
+}
+
+// registeredNames returns the names of the registered cleaners in order
+func registeredNames() []string {
+	var names []string
+	for _, cleaner := range Registered() {
+		names = append(names, cleaner.Name())
+	}
+	return names
+}
+
+func TestRegister(t *testing.T) {
+	builtIn := registeredNames()
+
+	tests := []struct {
+		name      string
+		register  []string
+		wantPanic bool
+		want      []string
+	}{
+		{
+			name:     "after the built-in cleaners in registration order",
+			register: []string{"quota", "images"},
+			want:     append(append([]string{}, builtIn...), "quota", "images"),
+		},
+		{
+			name:      "same name twice",
+			register:  []string{"quota", "quota"},
+			wantPanic: true,
+			want:      append(append([]string{}, builtIn...), "quota"),
+		},
+		{
+			name:      "name of a built-in cleaner",
+			register:  []string{"jobs"},
+			wantPanic: true,
+			want:      builtIn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreRegistry(t)
+
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				for _, name := range tt.register {
+					Register(&testCleaner{name: name})
+				}
+				return false
+			}()
+			if panicked != tt.wantPanic {
+				t.Errorf("got panic %v, want %v", panicked, tt.wantPanic)
+			}
+			if got := registeredNames(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("registered %v, want %v", got, tt.want)
+			}
+			if engine := NewEngine(NewWorkerPool(1, 0, 0)); len(engine.cleaners) != len(tt.want) {
+				t.Errorf("engine has %d cleaners, want %d", len(engine.cleaners), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDecodeConfigs(t *testing.T) {
+	tests := []struct {
+		name        string
+		extensions  map[string]string
+		wantConfigs map[string]interface{}
+		wantErrs    []string
+	}{
+		{
+			name:        "valid configuration",
+			extensions:  map[string]string{"quota": `{"threshold": 3}`},
+			wantConfigs: map[string]interface{}{"quota": &testExtensionConfig{Threshold: 3}},
+		},
+		{
+			name:        "configuration that fails to decode",
+			extensions:  map[string]string{"quota": `{"threshold": "three"}`},
+			wantConfigs: map[string]interface{}{},
+			wantErrs:    []string{"quota"},
+		},
+		{
+			name:        "unknown field",
+			extensions:  map[string]string{"quota": `{"limit": 3}`},
+			wantConfigs: map[string]interface{}{},
+			wantErrs:    []string{"quota"},
+		},
+		{
+			name:        "not configured",
+			wantConfigs: map[string]interface{}{},
+		},
+		{
+			name:        "configuration of a cleaner without a decoder",
+			extensions:  map[string]string{"plain": `{"threshold": 3}`},
+			wantConfigs: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Cleanup.Extensions = make(map[string]runtime.RawExtension)
+			for name, raw := range tt.extensions {
+				policy.Spec.Cleanup.Extensions[name] = runtime.RawExtension{Raw: []byte(raw)}
+			}
+			cleaners := []Cleaner{&testDecodingCleaner{testCleaner: testCleaner{name: "quota"}}, &testCleaner{name: "plain"}}
+
+			configs, errs := decodeConfigs(cleaners, policy)
+			if !reflect.DeepEqual(configs, tt.wantConfigs) {
+				t.Errorf("got configs %+v, want %+v", configs, tt.wantConfigs)
+			}
+			var failed []string
+			for name := range errs {
+				failed = append(failed, name)
+			}
+			if !reflect.DeepEqual(failed, tt.wantErrs) {
+				t.Errorf("got errors %v, want errors for %v", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestExecuteSkipsCleanerWithInvalidConfig(t *testing.T) {
+	valid := &testDecodingCleaner{testCleaner: testCleaner{name: "valid"}}
+	invalid := &testDecodingCleaner{testCleaner: testCleaner{name: "invalid"}}
+	engine := &Engine{cleaners: []Cleaner{valid, invalid}, locks: NewResourceLocks(), pool: NewWorkerPool(1, 0, 0)}
+
+	policy := &opsv1alpha1.JanitorPolicy{}
+	policy.Spec.DryRun = true
+	policy.Spec.Cleanup.Extensions = map[string]runtime.RawExtension{
+		"valid":   {Raw: []byte(`{"threshold": 3}`)},
+		"invalid": {Raw: []byte(`{"threshold": "three"}`)},
+	}
+
+	stats, err := engine.Execute(context.Background(), newTestContext(policy))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.ErrorsEncountered != 1 {
+		t.Errorf("got %d errors, want 1", stats.ErrorsEncountered)
+	}
+	if invalid.planned {
+		t.Error("cleaner with an invalid configuration was planned")
+	}
+	if !valid.planned || !reflect.DeepEqual(valid.config, &testExtensionConfig{Threshold: 3}) {
+		t.Errorf("valid cleaner planned %v with configuration %+v", valid.planned, valid.config)
+	}
+}
@@ -0,0 +1,333 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// checkedResources are the container resources inspected for gaps
+var checkedResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// workloadTemplate is a workload together with the pod spec of its template
+type workloadTemplate struct {
+	Object    client.Object
+	Kind      string
+	Spec      *corev1.PodSpec
+	Patchable bool
+}
+
+// limitRangeDefaults records which resources a namespace's LimitRanges default for containers
+type limitRangeDefaults struct {
+	Requests map[corev1.ResourceName]bool
+	Limits   map[corev1.ResourceName]bool
+}
+
+// ResourceGapsChecker detects workloads without resource limits/requests
+type ResourceGapsChecker struct{}
+
+// NewResourceGapsChecker creates a new resource gaps checker
+func NewResourceGapsChecker() *ResourceGapsChecker {
+	return &ResourceGapsChecker{}
+}
+
+// Name returns the name of the cleaner
+func (c *ResourceGapsChecker) Name() string {
+	return "resourcegaps"
+}
+
+// Enabled reports whether the policy enables the resource gaps check
+func (c *ResourceGapsChecker) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.ResourceGaps != nil && policy.Spec.Cleanup.ResourceGaps.Enabled
+}
+
+// Plan detects resource gaps and plans patching the default profile into workloads
+func (c *ResourceGapsChecker) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("resourcegaps-checker")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.ResourceGaps
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	checkRequests, checkLimits := c.checkedKinds(config.Check)
+
+	workloads, err := c.listWorkloads(ctx, cleanupCtx)
+	if err != nil {
+		log.Error(err, "Failed to list workloads")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	defaults, err := c.listLimitRangeDefaults(ctx, cleanupCtx)
+	if err != nil {
+		log.Error(err, "Failed to list LimitRanges")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	stats.Scanned = int32(len(workloads))
+
+	// Process each workload
+	var candidates []Candidate
+	for _, workload := range workloads {
+		obj := workload.Object
+		if IsNamespaceIgnored(obj.GetNamespace(), cleanupCtx.Policy.Spec.IgnoreNamespaces) ||
+			IsProtected(obj.GetLabels(), cleanupCtx.Policy.Spec.ProtectedLabels) {
+			stats.Skipped++
+			continue
+		}
+
+		nsDefaults := defaults[obj.GetNamespace()]
+		gaps := c.findGaps(workload.Spec, nsDefaults, checkRequests, checkLimits)
+		if len(gaps) == 0 {
+			continue
+		}
+
+		message := strings.Join(gaps, "; ")
+		log.Info("Workload has resource gaps", "kind", workload.Kind, "name", obj.GetName(), "namespace", obj.GetNamespace(), "gaps", message)
+		cleanupCtx.EventRecorder.Event(obj, "Warning", "ResourceGaps", message)
+		cleanupCtx.Report(opsv1alpha1.Finding{
+			Cleaner:   c.Name(),
+			Kind:      workload.Kind,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Reason:    "ResourceGaps",
+			Message:   message,
+		})
+
+		if config.ReportOnly || config.DefaultProfile == nil || !workload.Patchable {
+			stats.Skipped++
+			continue
+		}
+
+		spec := workload.Spec
+		candidates = append(candidates, Candidate{
+			Object:      obj,
+			Kind:        workload.Kind,
+			Description: "workload with resource gaps",
+			Action:      ActionPatch,
+			Reason:      "patching default resources: " + message,
+			Age:         time.Since(obj.GetCreationTimestamp().Time),
+			Rule:        "defaultProfile",
+			Mutate: func() {
+				c.applyProfile(spec, config.DefaultProfile, nsDefaults, checkRequests, checkLimits)
+			},
+		})
+	}
+
+	log.Info("Resource gaps planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// checkedKinds translates the Check list into whether requests and limits are inspected
+func (c *ResourceGapsChecker) checkedKinds(check []string) (requests, limits bool) {
+	if len(check) == 0 {
+		return true, true
+	}
+	for _, kind := range check {
+		switch kind {
+		case "requests":
+			requests = true
+		case "limits":
+			limits = true
+		case "both":
+			requests, limits = true, true
+		}
+	}
+	return requests, limits
+}
+
+// listWorkloads returns top-level workloads and bare pods with their pod templates.
+// Objects managed by another workload (ReplicaSets, CronJob Jobs, controlled pods) are left out.
+func (c *ResourceGapsChecker) listWorkloads(ctx context.Context, cleanupCtx *Context) ([]workloadTemplate, error) {
+	var workloads []workloadTemplate
+
+	var deploymentList appsv1.DeploymentList
+	if err := cleanupCtx.Client.List(ctx, &deploymentList); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deploymentList.Items {
+		d := &deploymentList.Items[i]
+		workloads = append(workloads, workloadTemplate{Object: d, Kind: "Deployment", Spec: &d.Spec.Template.Spec, Patchable: true})
+	}
+
+	var statefulSetList appsv1.StatefulSetList
+	if err := cleanupCtx.Client.List(ctx, &statefulSetList); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSetList.Items {
+		s := &statefulSetList.Items[i]
+		workloads = append(workloads, workloadTemplate{Object: s, Kind: "StatefulSet", Spec: &s.Spec.Template.Spec, Patchable: true})
+	}
+
+	var daemonSetList appsv1.DaemonSetList
+	if err := cleanupCtx.Client.List(ctx, &daemonSetList); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSetList.Items {
+		d := &daemonSetList.Items[i]
+		workloads = append(workloads, workloadTemplate{Object: d, Kind: "DaemonSet", Spec: &d.Spec.Template.Spec, Patchable: true})
+	}
+
+	var cronJobList batchv1.CronJobList
+	if err := cleanupCtx.Client.List(ctx, &cronJobList); err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobList.Items {
+		cj := &cronJobList.Items[i]
+		workloads = append(workloads, workloadTemplate{Object: cj, Kind: "CronJob", Spec: &cj.Spec.JobTemplate.Spec.Template.Spec, Patchable: true})
+	}
+
+	// Job templates are immutable, so Jobs are only reported
+	var jobList batchv1.JobList
+	if err := cleanupCtx.Client.List(ctx, &jobList); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobList.Items {
+		j := &jobList.Items[i]
+		if metav1.GetControllerOf(j) != nil {
+			continue
+		}
+		workloads = append(workloads, workloadTemplate{Object: j, Kind: "Job", Spec: &j.Spec.Template.Spec})
+	}
+
+	// Container resources of running pods cannot be added, so bare pods are only reported
+	var podList corev1.PodList
+	if err := cleanupCtx.Client.List(ctx, &podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range podList.Items {
+		p := &podList.Items[i]
+		if metav1.GetControllerOf(p) != nil || p.DeletionTimestamp != nil {
+			continue
+		}
+		workloads = append(workloads, workloadTemplate{Object: p, Kind: "Pod", Spec: &p.Spec})
+	}
+
+	return workloads, nil
+}
+
+// listLimitRangeDefaults returns, per namespace, the container resources defaulted by LimitRanges
+func (c *ResourceGapsChecker) listLimitRangeDefaults(ctx context.Context, cleanupCtx *Context) (map[string]limitRangeDefaults, error) {
+	var limitRangeList corev1.LimitRangeList
+	if err := cleanupCtx.Client.List(ctx, &limitRangeList); err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]limitRangeDefaults)
+	for _, lr := range limitRangeList.Items {
+		nsDefaults, exists := defaults[lr.Namespace]
+		if !exists {
+			nsDefaults = limitRangeDefaults{
+				Requests: make(map[corev1.ResourceName]bool),
+				Limits:   make(map[corev1.ResourceName]bool),
+			}
+			defaults[lr.Namespace] = nsDefaults
+		}
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			// A default limit also becomes the request when no default request is set
+			for name := range item.Default {
+				nsDefaults.Limits[name] = true
+				nsDefaults.Requests[name] = true
+			}
+			for name := range item.DefaultRequest {
+				nsDefaults.Requests[name] = true
+			}
+		}
+	}
+
+	return defaults, nil
+}
+
+// findGaps lists the containers of a pod spec that miss requests or limits not covered by LimitRange defaults
+func (c *ResourceGapsChecker) findGaps(spec *corev1.PodSpec, defaults limitRangeDefaults, checkRequests, checkLimits bool) []string {
+	var gaps []string
+
+	describe := func(kind string, container *corev1.Container) {
+		var missing []string
+		for _, name := range checkedResources {
+			if checkRequests && !defaults.Requests[name] {
+				if _, exists := container.Resources.Requests[name]; !exists {
+					missing = append(missing, "requests."+string(name))
+				}
+			}
+			if checkLimits && !defaults.Limits[name] {
+				if _, exists := container.Resources.Limits[name]; !exists {
+					missing = append(missing, "limits."+string(name))
+				}
+			}
+		}
+		if len(missing) > 0 {
+			gaps = append(gaps, fmt.Sprintf("%s %s missing %s", kind, container.Name, strings.Join(missing, ", ")))
+		}
+	}
+
+	for i := range spec.InitContainers {
+		describe("init container", &spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		describe("container", &spec.Containers[i])
+	}
+
+	return gaps
+}
+
+// applyProfile fills missing requests and limits from the profile, never letting a request exceed its limit
+func (c *ResourceGapsChecker) applyProfile(spec *corev1.PodSpec, profile *opsv1alpha1.ResourceProfile, defaults limitRangeDefaults, checkRequests, checkLimits bool) {
+	apply := func(container *corev1.Container) {
+		for _, name := range checkedResources {
+			if checkRequests && !defaults.Requests[name] {
+				if _, exists := container.Resources.Requests[name]; !exists {
+					if value, ok := profile.Requests[name]; ok {
+						if limit, hasLimit := container.Resources.Limits[name]; hasLimit && value.Cmp(limit) > 0 {
+							value = limit
+						}
+						if container.Resources.Requests == nil {
+							container.Resources.Requests = corev1.ResourceList{}
+						}
+						container.Resources.Requests[name] = value.DeepCopy()
+					}
+				}
+			}
+			if checkLimits && !defaults.Limits[name] {
+				if _, exists := container.Resources.Limits[name]; !exists {
+					if value, ok := profile.Limits[name]; ok {
+						if request, hasRequest := container.Resources.Requests[name]; hasRequest && value.Cmp(request) < 0 {
+							value = request
+						}
+						if container.Resources.Limits == nil {
+							container.Resources.Limits = corev1.ResourceList{}
+						}
+						container.Resources.Limits[name] = value.DeepCopy()
+					}
+				}
+			}
+		}
+	}
+
+	for i := range spec.InitContainers {
+		apply(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		apply(&spec.Containers[i])
+	}
+}
@@ -0,0 +1,339 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// resources builds a resource list from alternating names and quantities
+func resources(pairs ...string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for i := 0; i < len(pairs); i += 2 {
+		list[corev1.ResourceName(pairs[i])] = resource.MustParse(pairs[i+1])
+	}
+	return list
+}
+
+// limitRangeDefaultsOf builds LimitRange defaults from the defaulted request and limit names
+func limitRangeDefaultsOf(requests, limits []corev1.ResourceName) limitRangeDefaults {
+	defaults := limitRangeDefaults{Requests: map[corev1.ResourceName]bool{}, Limits: map[corev1.ResourceName]bool{}}
+	for _, name := range requests {
+		defaults.Requests[name] = true
+	}
+	for _, name := range limits {
+		defaults.Limits[name] = true
+	}
+	return defaults
+}
+
+func TestListLimitRangeDefaults(t *testing.T) {
+	limitRange := func(name, namespace string, items ...corev1.LimitRangeItem) client.Object {
+		return &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       corev1.LimitRangeSpec{Limits: items},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    map[string]limitRangeDefaults
+	}{
+		{
+			name: "default limit also defaults the request",
+			objects: []client.Object{limitRange("defaults", "apps", corev1.LimitRangeItem{
+				Type:    corev1.LimitTypeContainer,
+				Default: resources("memory", "256Mi"),
+			})},
+			want: map[string]limitRangeDefaults{
+				"apps": limitRangeDefaultsOf([]corev1.ResourceName{corev1.ResourceMemory}, []corev1.ResourceName{corev1.ResourceMemory}),
+			},
+		},
+		{
+			name: "default request only",
+			objects: []client.Object{limitRange("defaults", "apps", corev1.LimitRangeItem{
+				Type:           corev1.LimitTypeContainer,
+				DefaultRequest: resources("cpu", "100m"),
+			})},
+			want: map[string]limitRangeDefaults{
+				"apps": limitRangeDefaultsOf([]corev1.ResourceName{corev1.ResourceCPU}, nil),
+			},
+		},
+		{
+			name: "pod limits are not container defaults",
+			objects: []client.Object{limitRange("pods", "apps", corev1.LimitRangeItem{
+				Type: corev1.LimitTypePod,
+				Max:  resources("cpu", "2"),
+			})},
+			want: map[string]limitRangeDefaults{
+				"apps": limitRangeDefaultsOf(nil, nil),
+			},
+		},
+		{
+			name: "LimitRanges of a namespace are combined",
+			objects: []client.Object{
+				limitRange("cpu", "apps", corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, Default: resources("cpu", "500m")}),
+				limitRange("memory", "apps", corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, DefaultRequest: resources("memory", "128Mi")}),
+				limitRange("cpu", "batch", corev1.LimitRangeItem{Type: corev1.LimitTypeContainer, DefaultRequest: resources("cpu", "50m")}),
+			},
+			want: map[string]limitRangeDefaults{
+				"apps":  limitRangeDefaultsOf([]corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}, []corev1.ResourceName{corev1.ResourceCPU}),
+				"batch": limitRangeDefaultsOf([]corev1.ResourceName{corev1.ResourceCPU}, nil),
+			},
+		},
+		{
+			name: "no LimitRanges",
+			want: map[string]limitRangeDefaults{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupCtx := newTestContext(&opsv1alpha1.JanitorPolicy{}, tt.objects...)
+
+			got, err := NewResourceGapsChecker().listLimitRangeDefaults(context.Background(), cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	complete := corev1.ResourceRequirements{
+		Requests: resources("cpu", "100m", "memory", "128Mi"),
+		Limits:   resources("cpu", "500m", "memory", "256Mi"),
+	}
+
+	tests := []struct {
+		name          string
+		spec          corev1.PodSpec
+		defaults      limitRangeDefaults
+		checkRequests bool
+		checkLimits   bool
+		want          []string
+	}{
+		{
+			name:          "complete",
+			spec:          corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: complete}}},
+			checkRequests: true, checkLimits: true,
+		},
+		{
+			name:          "nothing set",
+			spec:          corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			checkRequests: true, checkLimits: true,
+			want: []string{"container app missing requests.cpu, limits.cpu, requests.memory, limits.memory"},
+		},
+		{
+			name:          "requests only",
+			spec:          corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			checkRequests: true,
+			want:          []string{"container app missing requests.cpu, requests.memory"},
+		},
+		{
+			name:          "covered by LimitRange defaults",
+			spec:          corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			defaults:      limitRangeDefaultsOf([]corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}, []corev1.ResourceName{corev1.ResourceMemory}),
+			checkRequests: true, checkLimits: true,
+			want: []string{"container app missing limits.cpu"},
+		},
+		{
+			name: "init container",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "migrate", Resources: corev1.ResourceRequirements{Limits: resources("memory", "64Mi")}}},
+				Containers:     []corev1.Container{{Name: "app", Resources: complete}},
+			},
+			checkLimits: true,
+			want:        []string{"init container migrate missing limits.cpu"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewResourceGapsChecker().findGaps(&tt.spec, tt.defaults, tt.checkRequests, tt.checkLimits)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	profile := &opsv1alpha1.ResourceProfile{
+		Requests: resources("cpu", "250m", "memory", "256Mi"),
+		Limits:   resources("cpu", "1", "memory", "512Mi"),
+	}
+
+	tests := []struct {
+		name          string
+		resources     corev1.ResourceRequirements
+		defaults      limitRangeDefaults
+		checkRequests bool
+		checkLimits   bool
+		want          corev1.ResourceRequirements
+	}{
+		{
+			name:          "nothing set",
+			checkRequests: true, checkLimits: true,
+			want: corev1.ResourceRequirements{Requests: profile.Requests, Limits: profile.Limits},
+		},
+		{
+			name:          "existing values are kept",
+			resources:     corev1.ResourceRequirements{Requests: resources("cpu", "100m"), Limits: resources("memory", "1Gi")},
+			checkRequests: true, checkLimits: true,
+			want: corev1.ResourceRequirements{
+				Requests: resources("cpu", "100m", "memory", "256Mi"),
+				Limits:   resources("cpu", "1", "memory", "1Gi"),
+			},
+		},
+		{
+			name:          "request clamped to a lower limit",
+			resources:     corev1.ResourceRequirements{Limits: resources("cpu", "100m", "memory", "128Mi")},
+			checkRequests: true, checkLimits: true,
+			want: corev1.ResourceRequirements{
+				Requests: resources("cpu", "100m", "memory", "128Mi"),
+				Limits:   resources("cpu", "100m", "memory", "128Mi"),
+			},
+		},
+		{
+			name:          "limit raised to a higher request",
+			resources:     corev1.ResourceRequirements{Requests: resources("cpu", "2", "memory", "1Gi")},
+			checkRequests: true, checkLimits: true,
+			want: corev1.ResourceRequirements{
+				Requests: resources("cpu", "2", "memory", "1Gi"),
+				Limits:   resources("cpu", "2", "memory", "1Gi"),
+			},
+		},
+		{
+			name:          "LimitRange defaults are left to the LimitRange",
+			defaults:      limitRangeDefaultsOf([]corev1.ResourceName{corev1.ResourceMemory}, []corev1.ResourceName{corev1.ResourceMemory}),
+			checkRequests: true, checkLimits: true,
+			want: corev1.ResourceRequirements{Requests: resources("cpu", "250m"), Limits: resources("cpu", "1")},
+		},
+		{
+			name:          "requests only",
+			checkRequests: true,
+			want:          corev1.ResourceRequirements{Requests: profile.Requests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "migrate", Resources: *tt.resources.DeepCopy()}},
+				Containers:     []corev1.Container{{Name: "app", Resources: *tt.resources.DeepCopy()}},
+			}
+
+			NewResourceGapsChecker().applyProfile(spec, profile, tt.defaults, tt.checkRequests, tt.checkLimits)
+			for _, container := range append(spec.InitContainers, spec.Containers...) {
+				if !equalResources(container.Resources.Requests, tt.want.Requests) || !equalResources(container.Resources.Limits, tt.want.Limits) {
+					t.Errorf("container %s got %+v, want %+v", container.Name, container.Resources, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// equalResources compares resource lists by quantity value
+func equalResources(got, want corev1.ResourceList) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for name, quantity := range want {
+		if value, exists := got[name]; !exists || value.Cmp(quantity) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestResourceGapsPlan(t *testing.T) {
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}}
+	objects := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"}, Spec: appsv1.DeploymentSpec{Template: template}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"}, Spec: appsv1.StatefulSetSpec{Template: template}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "apps"}, Spec: appsv1.DaemonSetSpec{Template: template}},
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "apps"}, Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template}},
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "apps"}, Spec: batchv1.JobSpec{Template: template}},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "report-28000000", Namespace: "apps", OwnerReferences: []metav1.OwnerReference{*controllerRef("CronJob", "report")}},
+			Spec:       batchv1.JobSpec{Template: template},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "apps"}, Spec: template.Spec},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5d4f-a", Namespace: "apps", OwnerReferences: []metav1.OwnerReference{*controllerRef("ReplicaSet", "web-5d4f")}},
+			Spec:       template.Spec,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		reportOnly bool
+		profile    *opsv1alpha1.ResourceProfile
+		// wantPlanned lists the workloads planned to be patched as "kind/name"
+		wantPlanned []string
+	}{
+		{
+			name:        "patchable kinds are patched, Jobs and bare pods only reported",
+			profile:     &opsv1alpha1.ResourceProfile{Requests: resources("cpu", "100m")},
+			wantPlanned: []string{"CronJob/report", "DaemonSet/agent", "Deployment/web", "StatefulSet/db"},
+		},
+		{
+			name:       "report only",
+			reportOnly: true,
+			profile:    &opsv1alpha1.ResourceProfile{Requests: resources("cpu", "100m")},
+		},
+		{
+			name: "without a profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Cleanup.ResourceGaps = &opsv1alpha1.ResourceGapsConfig{Enabled: true, ReportOnly: tt.reportOnly, DefaultProfile: tt.profile}
+			cleanupCtx := newTestContext(policy, objects...)
+
+			candidates, _, err := NewResourceGapsChecker().Plan(context.Background(), cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var planned []string
+			for _, candidate := range candidates {
+				planned = append(planned, candidate.Kind+"/"+candidate.Object.GetName())
+			}
+			sort.Strings(planned)
+			if !reflect.DeepEqual(planned, tt.wantPlanned) {
+				t.Errorf("planned %v, want %v", planned, tt.wantPlanned)
+			}
+
+			// Every workload with gaps is reported, whether it is patched or not
+			var reported []string
+			for _, finding := range cleanupCtx.Findings() {
+				reported = append(reported, finding.Kind+"/"+finding.Name)
+			}
+			sort.Strings(reported)
+			wantReported := []string{"CronJob/report", "DaemonSet/agent", "Deployment/web", "Job/migrate", "Pod/debug", "StatefulSet/db"}
+			if !reflect.DeepEqual(reported, wantReported) {
+				t.Errorf("reported %v, want %v", reported, wantReported)
+			}
+		})
+	}
+}
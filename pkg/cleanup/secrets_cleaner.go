@@ -0,0 +1,256 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// helmReleaseSecretType is the Secret type used by Helm 3 for release storage
+	helmReleaseSecretType corev1.SecretType = "helm.sh/release.v1"
+)
+
+// alwaysExcludedSecretTypes are Secret types that are never cleaned up, regardless of ExcludeTypes
+var alwaysExcludedSecretTypes = []corev1.SecretType{
+	corev1.SecretTypeServiceAccountToken,
+	corev1.SecretTypeBootstrapToken,
+	helmReleaseSecretType,
+}
+
+// SecretsCleaner handles cleanup of unused Secrets
+type SecretsCleaner struct{}
+
+// NewSecretsCleaner creates a new Secrets cleaner
+func NewSecretsCleaner() *SecretsCleaner {
+	return &SecretsCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *SecretsCleaner) Name() string {
+	return "secrets"
+}
+
+// Enabled reports whether the policy enables Secrets cleanup
+func (c *SecretsCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.Secrets != nil && policy.Spec.Cleanup.Secrets.Enabled
+}
+
+// Plan finds unused Secrets to delete
+func (c *SecretsCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("secrets-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.Secrets
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	// Parse duration
+	olderThan, err := time.ParseDuration(config.OlderThan)
+	if err != nil {
+		log.Error(err, "Failed to parse olderThan duration", "duration", config.OlderThan)
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	cutoffTime := time.Now().Add(-olderThan)
+
+	// Get all Secrets
+	var secretList corev1.SecretList
+	if err := cleanupCtx.Client.List(ctx, &secretList); err != nil {
+		log.Error(err, "Failed to list Secrets")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	stats.Scanned = int32(len(secretList.Items))
+
+	// Build map of referenced Secrets
+	referenced := make(map[string]bool)
+	if config.CheckReferences {
+		referenced, err = c.buildReferences(ctx, cleanupCtx, secretList.Items)
+		if err != nil {
+			log.Error(err, "Failed to build Secret reference graph")
+			stats.Errors++
+			return nil, stats, err
+		}
+	}
+
+	reason := "older than " + config.OlderThan
+	if config.CheckReferences {
+		reason = "not referenced by any workload"
+	}
+
+	// Process each Secret
+	var candidates []Candidate
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if c.shouldSkipSecret(secret, config, cleanupCtx) {
+			log.V(1).Info("Skipping Secret", "name", secret.Name, "namespace", secret.Namespace, "type", secret.Type)
+			stats.Skipped++
+			continue
+		}
+
+		// Check if Secret is referenced
+		if referenced[secret.Namespace+"/"+secret.Name] {
+			log.V(1).Info("Secret is referenced, skipping", "name", secret.Name, "namespace", secret.Namespace)
+			stats.Skipped++
+			continue
+		}
+
+		// Check if Secret is old enough
+		if secret.CreationTimestamp.Time.After(cutoffTime) {
+			log.V(1).Info("Secret is too new, skipping", "name", secret.Name, "namespace", secret.Namespace, "age", time.Since(secret.CreationTimestamp.Time))
+			stats.Skipped++
+			continue
+		}
+
+		// Secret is unreferenced and old enough to be cleaned
+		candidates = append(candidates, Candidate{
+			Object:      secret,
+			Kind:        "Secret",
+			Description: "unused Secret",
+			Action:      ActionDelete,
+			Reason:      reason,
+			Age:         time.Since(secret.CreationTimestamp.Time),
+			Rule:        "olderThan=" + config.OlderThan,
+		})
+	}
+
+	log.Info("Secrets planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// buildReferences collects all Secrets referenced by pod templates, ServiceAccounts, Ingress TLS entries,
+// Gateway listener certificates and Helm releases. Of the given Secrets, those rendered by a Helm chart
+// are referenced by their release.
+func (c *SecretsCleaner) buildReferences(ctx context.Context, cleanupCtx *Context, secrets []corev1.Secret) (map[string]bool, error) {
+	specs, err := listPodSpecs(ctx, cleanupCtx.Client)
+	if err != nil {
+		return nil, err
+	}
+	referenced := secretReferences(specs)
+
+	var serviceAccountList corev1.ServiceAccountList
+	if err := cleanupCtx.Client.List(ctx, &serviceAccountList); err != nil {
+		return nil, fmt.Errorf("failed to list serviceaccounts: %w", err)
+	}
+	for _, sa := range serviceAccountList.Items {
+		for _, ref := range sa.Secrets {
+			referenced[sa.Namespace+"/"+ref.Name] = true
+		}
+		for _, ref := range sa.ImagePullSecrets {
+			referenced[sa.Namespace+"/"+ref.Name] = true
+		}
+	}
+
+	var ingressList networkingv1.IngressList
+	if err := cleanupCtx.Client.List(ctx, &ingressList); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ingress := range ingressList.Items {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				referenced[ingress.Namespace+"/"+tls.SecretName] = true
+			}
+		}
+	}
+
+	gatewayRefs, err := gatewayCertificateReferences(ctx, cleanupCtx.Client)
+	if err != nil {
+		return nil, err
+	}
+	for key := range gatewayRefs {
+		referenced[key] = true
+	}
+
+	for i := range secrets {
+		if helmManaged(&secrets[i]) {
+			referenced[secrets[i].Namespace+"/"+secrets[i].Name] = true
+		}
+	}
+
+	return referenced, nil
+}
+
+// shouldSkipSecret determines if a Secret should be skipped
+func (c *SecretsCleaner) shouldSkipSecret(secret *corev1.Secret, config *opsv1alpha1.SecretsCleanupConfig, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if IsNamespaceIgnored(secret.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if Secret has protected labels
+	if IsProtected(secret.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	// Check excluded types
+	if c.isExcludedType(secret.Type, config.ExcludeTypes) {
+		return true
+	}
+
+	// Skip if Secret is in terminating state
+	if secret.DeletionTimestamp != nil {
+		return true
+	}
+
+	// Owned Secrets are garbage collected together with their owner
+	if len(secret.OwnerReferences) > 0 {
+		return true
+	}
+
+	// Skip system Secrets
+	if c.isSystemSecret(secret) {
+		return true
+	}
+
+	return false
+}
+
+// isExcludedType checks if a Secret type is excluded from cleanup
+func (c *SecretsCleaner) isExcludedType(secretType corev1.SecretType, excludeTypes []string) bool {
+	for _, excluded := range alwaysExcludedSecretTypes {
+		if secretType == excluded {
+			return true
+		}
+	}
+	for _, excluded := range excludeTypes {
+		if string(secretType) == excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// isSystemSecret checks if a Secret is a system Secret that should not be deleted
+func (c *SecretsCleaner) isSystemSecret(secret *corev1.Secret) bool {
+	// Service account tokens created through the legacy annotation flow
+	if _, exists := secret.Annotations[corev1.ServiceAccountNameKey]; exists {
+		return true
+	}
+
+	// Check for system labels
+	if secret.Labels != nil {
+		if component := secret.Labels["k8s-app"]; component != "" {
+			return true
+		}
+		// Helm release storage, even if stored with a non-standard type
+		if owner := secret.Labels["owner"]; owner == "helm" {
+			return true
+		}
+	}
+
+	return false
+}
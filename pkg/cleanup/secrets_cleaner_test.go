@@ -0,0 +1,138 @@
+package cleanup
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestSecretsBuildReferences(t *testing.T) {
+	secret := func(name string, labels, annotations map[string]string) corev1.Secret {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", Labels: labels, Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		secrets []corev1.Secret
+		want    []string
+	}{
+		{
+			name: "pod volume and environment",
+			objects: []client.Object{&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "web-tls"}}}},
+					Containers: []corev1.Container{{
+						Name:    "web",
+						EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-env"}}}},
+					}},
+				},
+			}},
+			want: []string{"apps/web-tls", "apps/web-env"},
+		},
+		{
+			name: "scaled-down Deployment template",
+			objects: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "apps"},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+				}}},
+			}},
+			want: []string{"apps/registry"},
+		},
+		{
+			name: "ServiceAccount",
+			objects: []client.Object{&corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "ci"},
+				Secrets:          []corev1.ObjectReference{{Name: "builder-token"}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "builder-registry"}},
+			}},
+			want: []string{"ci/builder-token", "ci/builder-registry"},
+		},
+		{
+			name: "Ingress TLS",
+			objects: []client.Object{&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+				Spec:       networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{{SecretName: "web-cert"}, {}}},
+			}},
+			want: []string{"apps/web-cert"},
+		},
+		{
+			name: "rendered by a Helm chart",
+			secrets: []corev1.Secret{
+				secret("labelled", map[string]string{"app.kubernetes.io/managed-by": "Helm"}, nil),
+				secret("annotated", nil, map[string]string{"meta.helm.sh/release-name": "web"}),
+				secret("other-manager", map[string]string{"app.kubernetes.io/managed-by": "kustomize"}, nil),
+				secret("plain", nil, nil),
+			},
+			want: []string{"apps/labelled", "apps/annotated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupCtx := newTestContext(&opsv1alpha1.JanitorPolicy{}, tt.objects...)
+
+			referenced, err := NewSecretsCleaner().buildReferences(context.Background(), cleanupCtx, tt.secrets)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, key := range tt.want {
+				if !referenced[key] {
+					t.Errorf("%s is not referenced", key)
+				}
+			}
+			if len(referenced) != len(tt.want) {
+				t.Errorf("got references %v, want %v", referenced, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldSkipSecret(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name   string
+		secret corev1.Secret
+		want   bool
+	}{
+		{name: "opaque", secret: corev1.Secret{Type: corev1.SecretTypeOpaque}},
+		{name: "ignored namespace", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system"}}, want: true},
+		{name: "protected label", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"janitor.k8s.io/keep": "true"}}}, want: true},
+		{name: "excluded type", secret: corev1.Secret{Type: corev1.SecretTypeDockerConfigJson}, want: true},
+		{name: "service account token", secret: corev1.Secret{Type: corev1.SecretTypeServiceAccountToken}, want: true},
+		{name: "bootstrap token", secret: corev1.Secret{Type: corev1.SecretTypeBootstrapToken}, want: true},
+		{name: "Helm release storage", secret: corev1.Secret{Type: helmReleaseSecretType}, want: true},
+		{name: "Helm release storage with another type", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"owner": "helm"}}}, want: true},
+		{name: "legacy token annotation", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{corev1.ServiceAccountNameKey: "default"}}}, want: true},
+		{name: "system component", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"k8s-app": "metrics-server"}}}, want: true},
+		{name: "owned", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: "Certificate", Name: "web"}}}}, want: true},
+		{name: "terminating", secret: corev1.Secret{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.IgnoreNamespaces = []string{"kube-system"}
+			policy.Spec.ProtectedLabels = []string{"janitor.k8s.io/keep"}
+			config := &opsv1alpha1.SecretsCleanupConfig{Enabled: true, ExcludeTypes: []string{string(corev1.SecretTypeDockerConfigJson)}}
+
+			secret := tt.secret
+			if secret.Namespace == "" {
+				secret.Namespace = "apps"
+			}
+			if got := NewSecretsCleaner().shouldSkipSecret(&secret, config, newTestContext(policy)); got != tt.want {
+				t.Errorf("got skip %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// defaultServiceEmptyFor is used when ServicesCleanupConfig.EmptyFor is not set
+	defaultServiceEmptyFor = time.Hour
+
+	// emptySinceAnnotationPrefix, followed by the policy UID, records when the policy first found a Service without backends
+	emptySinceAnnotationPrefix = "janitor.io/empty-since-"
+)
+
+// gatewayRouteKinds are the Gateway API route list kinds whose backendRefs can point at Services
+var gatewayRouteKinds = []schema.GroupVersionKind{
+	{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRouteList"},
+	{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "GRPCRouteList"},
+	{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "TLSRouteList"},
+	{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "TCPRouteList"},
+	{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "UDPRouteList"},
+}
+
+// ServicesCleaner handles cleanup of orphaned Services.
+// The time a Service was first found empty is kept in an annotation on the Service, per policy,
+// so it survives operator restarts and policies with different settings do not share it.
+type ServicesCleaner struct{}
+
+// NewServicesCleaner creates a new Services cleaner
+func NewServicesCleaner() *ServicesCleaner {
+	return &ServicesCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *ServicesCleaner) Name() string {
+	return "services"
+}
+
+// Enabled reports whether the policy enables Services cleanup
+func (c *ServicesCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.Services != nil && policy.Spec.Cleanup.Services.Enabled
+}
+
+// Plan finds orphaned Services to delete
+func (c *ServicesCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("services-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.Services
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	// Parse duration
+	emptyFor := defaultServiceEmptyFor
+	if config.EmptyFor != "" {
+		parsed, err := time.ParseDuration(config.EmptyFor)
+		if err != nil {
+			log.Error(err, "Failed to parse emptyFor duration", "duration", config.EmptyFor)
+			stats.Errors++
+			return nil, stats, err
+		}
+		emptyFor = parsed
+	}
+
+	// Get all Services
+	var serviceList corev1.ServiceList
+	if err := cleanupCtx.Client.List(ctx, &serviceList); err != nil {
+		log.Error(err, "Failed to list Services")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	stats.Scanned = int32(len(serviceList.Items))
+
+	// Get all pods to check selector matches
+	var podList corev1.PodList
+	if err := cleanupCtx.Client.List(ctx, &podList); err != nil {
+		log.Error(err, "Failed to list pods")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	podsByNamespace := make(map[string][]corev1.Pod)
+	for _, pod := range podList.Items {
+		podsByNamespace[pod.Namespace] = append(podsByNamespace[pod.Namespace], pod)
+	}
+
+	// Count ready endpoints per Service and find when their endpoints last changed
+	readyEndpoints, lastEndpointChange, err := c.endpointState(ctx, cleanupCtx)
+	if err != nil {
+		log.Error(err, "Failed to list EndpointSlices")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	// Build map of Services referenced by routes
+	routed, err := c.routedServices(ctx, cleanupCtx)
+	if err != nil {
+		log.Error(err, "Failed to list Service route references")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	now := time.Now()
+
+	// Process each Service
+	var candidates []Candidate
+	for i := range serviceList.Items {
+		svc := &serviceList.Items[i]
+		svcKey := svc.Namespace + "/" + svc.Name
+
+		if c.shouldSkipService(svc, cleanupCtx) {
+			log.V(1).Info("Skipping Service", "name", svc.Name, "namespace", svc.Namespace)
+			stats.Skipped++
+			continue
+		}
+
+		// Check if Service is referenced by an Ingress or Gateway route
+		if routed[svcKey] {
+			log.V(1).Info("Service is referenced by a route, skipping", "name", svc.Name, "namespace", svc.Namespace)
+			if err := c.markPopulated(ctx, cleanupCtx, svc); err != nil {
+				log.Error(err, "Failed to clear empty Service tracking", "name", svc.Name, "namespace", svc.Namespace)
+				stats.Errors++
+			}
+			stats.Skipped++
+			continue
+		}
+
+		// Check if the Service still has backends
+		if c.selectsAnyPod(svc, podsByNamespace[svc.Namespace]) || (config.CheckEndpoints && readyEndpoints[svcKey] > 0) {
+			log.V(1).Info("Service has backends, skipping", "name", svc.Name, "namespace", svc.Namespace)
+			if err := c.markPopulated(ctx, cleanupCtx, svc); err != nil {
+				log.Error(err, "Failed to clear empty Service tracking", "name", svc.Name, "namespace", svc.Namespace)
+				stats.Errors++
+			}
+			stats.Skipped++
+			continue
+		}
+
+		// Check if Service has been empty long enough
+		since, err := c.markEmpty(ctx, cleanupCtx, svc, now, lastEndpointChange[svcKey])
+		if err != nil {
+			log.Error(err, "Failed to record empty Service", "name", svc.Name, "namespace", svc.Namespace)
+			stats.Errors++
+			continue
+		}
+		if now.Sub(since) < emptyFor {
+			log.V(1).Info("Service has not been empty long enough, skipping", "name", svc.Name, "namespace", svc.Namespace, "emptySince", since)
+			stats.Skipped++
+			continue
+		}
+
+		// Service is orphaned and has been empty long enough to be cleaned
+		candidates = append(candidates, Candidate{
+			Object:      svc,
+			Kind:        "Service",
+			Description: "orphaned Service",
+			Action:      ActionDelete,
+			Reason:      "no backends and not referenced by any route",
+			Age:         now.Sub(since),
+			Rule:        "emptyFor=" + emptyFor.String(),
+		})
+	}
+
+	log.Info("Services planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// shouldSkipService determines if a Service should be skipped
+func (c *ServicesCleaner) shouldSkipService(svc *corev1.Service, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if IsNamespaceIgnored(svc.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if Service has protected labels
+	if IsProtected(svc.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	// Skip if Service is in terminating state
+	if svc.DeletionTimestamp != nil {
+		return true
+	}
+
+	// ExternalName Services never have endpoints
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return true
+	}
+
+	// Selector-less Services have manually managed endpoints
+	if len(svc.Spec.Selector) == 0 {
+		return true
+	}
+
+	// Owned Services are garbage collected together with their owner
+	if len(svc.OwnerReferences) > 0 {
+		return true
+	}
+
+	// Skip system Services
+	if c.isSystemService(svc) {
+		return true
+	}
+
+	return false
+}
+
+// isSystemService checks if a Service is a system Service that should not be deleted
+func (c *ServicesCleaner) isSystemService(svc *corev1.Service) bool {
+	// The API server Service
+	if svc.Namespace == "default" && svc.Name == "kubernetes" {
+		return true
+	}
+
+	// Check for system labels
+	if svc.Labels != nil {
+		if component := svc.Labels["k8s-app"]; component != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectsAnyPod checks if the Service selector matches at least one Pod
+func (c *ServicesCleaner) selectsAnyPod(svc *corev1.Service, pods []corev1.Pod) bool {
+	selector := labels.SelectorFromSet(svc.Spec.Selector)
+	for _, pod := range pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointState returns the number of ready endpoints per Service and the last time the endpoints of
+// each Service changed, both keyed by "namespace/name"
+func (c *ServicesCleaner) endpointState(ctx context.Context, cleanupCtx *Context) (map[string]int, map[string]time.Time, error) {
+	var sliceList discoveryv1.EndpointSliceList
+	if err := cleanupCtx.Client.List(ctx, &sliceList); err != nil {
+		return nil, nil, fmt.Errorf("failed to list endpointslices: %w", err)
+	}
+
+	counts := make(map[string]int)
+	lastChange := make(map[string]time.Time)
+	for _, slice := range sliceList.Items {
+		serviceName := slice.Labels[discoveryv1.LabelServiceName]
+		if serviceName == "" {
+			continue
+		}
+		svcKey := slice.Namespace + "/" + serviceName
+
+		// Set by the EndpointSlice controller whenever Pods join or leave the Service
+		if changed, err := time.Parse(time.RFC3339Nano, slice.Annotations[corev1.EndpointsLastChangeTriggerTime]); err == nil && changed.After(lastChange[svcKey]) {
+			lastChange[svcKey] = changed
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			// A nil ready condition must be interpreted as ready
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				counts[svcKey]++
+			}
+		}
+	}
+
+	return counts, lastChange, nil
+}
+
+// routedServices returns the Services referenced by Ingresses and Gateway API routes, keyed by "namespace/name"
+func (c *ServicesCleaner) routedServices(ctx context.Context, cleanupCtx *Context) (map[string]bool, error) {
+	routed := make(map[string]bool)
+
+	var ingressList networkingv1.IngressList
+	if err := cleanupCtx.Client.List(ctx, &ingressList); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ingress := range ingressList.Items {
+		if backend := ingress.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+			routed[ingress.Namespace+"/"+backend.Service.Name] = true
+		}
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					routed[ingress.Namespace+"/"+path.Backend.Service.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, gvk := range gatewayRouteKinds {
+		routeList := &unstructured.UnstructuredList{}
+		routeList.SetGroupVersionKind(gvk)
+		if err := cleanupCtx.Client.List(ctx, routeList); err != nil {
+			// Gateway API is optional; skip route kinds that are not installed
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+		for _, route := range routeList.Items {
+			for _, key := range routeBackendServices(&route) {
+				routed[key] = true
+			}
+		}
+	}
+
+	return routed, nil
+}
+
+// routeBackendServices extracts the "namespace/name" keys of Services referenced by a Gateway API route
+func routeBackendServices(route *unstructured.Unstructured) []string {
+	var keys []string
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backendRefs, _, _ := unstructured.NestedSlice(ruleMap, "backendRefs")
+		for _, ref := range backendRefs {
+			refMap, ok := ref.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, _, _ := unstructured.NestedString(refMap, "group")
+			kind, _, _ := unstructured.NestedString(refMap, "kind")
+			if group != "" || (kind != "" && kind != "Service") {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(refMap, "name")
+			namespace, _, _ := unstructured.NestedString(refMap, "namespace")
+			if namespace == "" {
+				namespace = route.GetNamespace()
+			}
+			if name != "" {
+				keys = append(keys, namespace+"/"+name)
+			}
+		}
+	}
+
+	return keys
+}
+
+// emptySinceAnnotation returns the annotation in which the policy records when a Service was first found empty
+func emptySinceAnnotation(policy *opsv1alpha1.JanitorPolicy) string {
+	return emptySinceAnnotationPrefix + string(policy.UID)
+}
+
+// markEmpty records that a Service has no backends and returns since when it has been empty.
+// The clock starts over when the endpoints of the Service changed after it was first found empty,
+// since Pods may have come and gone between two runs.
+func (c *ServicesCleaner) markEmpty(ctx context.Context, cleanupCtx *Context, svc *corev1.Service, now, lastEndpointChange time.Time) (time.Time, error) {
+	key := emptySinceAnnotation(cleanupCtx.Policy)
+
+	// The annotation has a precision of one second
+	changed := lastEndpointChange.Truncate(time.Second)
+	since, err := time.Parse(time.RFC3339, svc.Annotations[key])
+	switch {
+	case err != nil:
+		since = now
+	case changed.After(since):
+		since = changed
+	default:
+		return since, nil
+	}
+
+	// Patching updates the resource version of svc, which the deletion is then conditioned on
+	patch := client.MergeFrom(svc.DeepCopy())
+	if svc.Annotations == nil {
+		svc.Annotations = make(map[string]string)
+	}
+	svc.Annotations[key] = since.UTC().Format(time.RFC3339)
+	if err := cleanupCtx.Client.Patch(ctx, svc, patch); err != nil {
+		return time.Time{}, fmt.Errorf("failed to annotate Service %s/%s: %w", svc.Namespace, svc.Name, err)
+	}
+	return since, nil
+}
+
+// markPopulated clears the empty tracking of a Service that has backends again
+func (c *ServicesCleaner) markPopulated(ctx context.Context, cleanupCtx *Context, svc *corev1.Service) error {
+	key := emptySinceAnnotation(cleanupCtx.Policy)
+	if _, exists := svc.Annotations[key]; !exists {
+		return nil
+	}
+
+	patch := client.MergeFrom(svc.DeepCopy())
+	delete(svc.Annotations, key)
+	if err := cleanupCtx.Client.Patch(ctx, svc, patch); err != nil {
+		return fmt.Errorf("failed to annotate Service %s/%s: %w", svc.Namespace, svc.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package cleanup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// newTestService returns a Service selecting app=name, with the given annotations
+func newTestService(name string, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", Annotations: annotations},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": name}},
+	}
+}
+
+func TestServicesMarkEmpty(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	policy := &opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{UID: "policy-a"}}
+	key := emptySinceAnnotation(policy)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		lastChange  time.Time
+		want        time.Time
+	}{
+		{
+			name: "first seen empty",
+			want: now,
+		},
+		{
+			name:        "already empty",
+			annotations: map[string]string{key: "2024-06-03T10:00:00Z"},
+			want:        now.Add(-2 * time.Hour),
+		},
+		{
+			name:        "endpoints changed before it was found empty",
+			annotations: map[string]string{key: "2024-06-03T10:00:00Z"},
+			lastChange:  now.Add(-3 * time.Hour),
+			want:        now.Add(-2 * time.Hour),
+		},
+		{
+			name:        "endpoints changed since it was found empty",
+			annotations: map[string]string{key: "2024-06-03T10:00:00Z"},
+			lastChange:  now.Add(-30*time.Minute + 500*time.Millisecond),
+			want:        now.Add(-30 * time.Minute),
+		},
+		{
+			name:        "tracked by another policy",
+			annotations: map[string]string{emptySinceAnnotationPrefix + "policy-b": "2024-06-03T10:00:00Z"},
+			want:        now,
+		},
+		{
+			name:        "unreadable annotation",
+			annotations: map[string]string{key: "yesterday"},
+			want:        now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService("web", tt.annotations)
+			cleanupCtx := newTestContext(policy, svc)
+
+			since, err := NewServicesCleaner().markEmpty(context.Background(), cleanupCtx, svc, now, tt.lastChange)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !since.Equal(tt.want) {
+				t.Errorf("empty since %s, want %s", since, tt.want)
+			}
+
+			var stored corev1.Service
+			if err := cleanupCtx.Client.Get(context.Background(), client.ObjectKeyFromObject(svc), &stored); err != nil {
+				t.Fatal(err)
+			}
+			if got := stored.Annotations[key]; got != tt.want.Format(time.RFC3339) {
+				t.Errorf("stored %q, want %q", got, tt.want.Format(time.RFC3339))
+			}
+			if stored.ResourceVersion != svc.ResourceVersion {
+				t.Errorf("Service has resource version %s, the stored one is %s", svc.ResourceVersion, stored.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestServicesPlan(t *testing.T) {
+	policy := &opsv1alpha1.JanitorPolicy{ObjectMeta: metav1.ObjectMeta{UID: "policy-a"}}
+	policy.Spec.Cleanup.Services = &opsv1alpha1.ServicesCleanupConfig{Enabled: true, CheckEndpoints: true, EmptyFor: "1h"}
+	key := emptySinceAnnotation(policy)
+	longAgo := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+
+	endpointSlice := func(service string, changed time.Time, ready bool) *discoveryv1.EndpointSlice {
+		slice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        service + "-abcde",
+				Namespace:   "apps",
+				Labels:      map[string]string{discoveryv1.LabelServiceName: service},
+				Annotations: map[string]string{corev1.EndpointsLastChangeTriggerTime: changed.UTC().Format(time.RFC3339Nano)},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+		}
+		if ready {
+			slice.Endpoints = []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}}
+		}
+		return slice
+	}
+
+	tests := []struct {
+		name           string
+		objects        []client.Object
+		wantCandidates []string
+		// wantTracked lists the Services annotated as empty after planning
+		wantTracked []string
+	}{
+		{
+			name:        "found empty for the first time",
+			objects:     []client.Object{newTestService("web", nil)},
+			wantTracked: []string{"web"},
+		},
+		{
+			name:           "empty for longer than emptyFor",
+			objects:        []client.Object{newTestService("web", map[string]string{key: longAgo})},
+			wantCandidates: []string{"web"},
+			wantTracked:    []string{"web"},
+		},
+		{
+			name: "empty for longer than emptyFor for another policy only",
+			objects: []client.Object{
+				newTestService("web", map[string]string{emptySinceAnnotationPrefix + "policy-b": longAgo}),
+			},
+			wantTracked: []string{"web"},
+		},
+		{
+			name: "Pods came and went since it was found empty",
+			objects: []client.Object{
+				newTestService("web", map[string]string{key: longAgo}),
+				endpointSlice("web", time.Now().Add(-10*time.Minute), false),
+			},
+			wantTracked: []string{"web"},
+		},
+		{
+			name: "selects a Pod again",
+			objects: []client.Object{
+				newTestService("web", map[string]string{key: longAgo}),
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "apps", Labels: map[string]string{"app": "web"}}},
+			},
+		},
+		{
+			name: "ready endpoints again",
+			objects: []client.Object{
+				newTestService("web", map[string]string{key: longAgo}),
+				endpointSlice("web", time.Now().Add(-10*time.Minute), true),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleanupCtx := newTestContext(policy, tt.objects...)
+
+			candidates, _, err := NewServicesCleaner().Plan(context.Background(), cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, candidate := range candidates {
+				names = append(names, candidate.Object.GetName())
+			}
+			if !reflect.DeepEqual(names, tt.wantCandidates) {
+				t.Errorf("got candidates %v, want %v", names, tt.wantCandidates)
+			}
+
+			var serviceList corev1.ServiceList
+			if err := cleanupCtx.Client.List(context.Background(), &serviceList); err != nil {
+				t.Fatal(err)
+			}
+			var tracked []string
+			for _, svc := range serviceList.Items {
+				if _, exists := svc.Annotations[key]; exists {
+					tracked = append(tracked, svc.Name)
+				}
+			}
+			if !reflect.DeepEqual(tracked, tt.wantTracked) {
+				t.Errorf("got tracked Services %v, want %v", tracked, tt.wantTracked)
+			}
+		})
+	}
+}
+
+func TestRouteBackendServices(t *testing.T) {
+	tests := []struct {
+		name        string
+		backendRefs []interface{}
+		want        []string
+	}{
+		{
+			name:        "Service in the route namespace",
+			backendRefs: []interface{}{map[string]interface{}{"name": "web", "port": int64(80)}},
+			want:        []string{"apps/web"},
+		},
+		{
+			name:        "Service in another namespace",
+			backendRefs: []interface{}{map[string]interface{}{"name": "api", "namespace": "backend"}},
+			want:        []string{"backend/api"},
+		},
+		{
+			name:        "explicit Service kind",
+			backendRefs: []interface{}{map[string]interface{}{"kind": "Service", "group": "", "name": "web"}},
+			want:        []string{"apps/web"},
+		},
+		{
+			name: "other backend kinds",
+			backendRefs: []interface{}{
+				map[string]interface{}{"kind": "ServiceImport", "group": "multicluster.x-k8s.io", "name": "web"},
+				map[string]interface{}{"kind": "Bucket", "name": "assets"},
+			},
+		},
+		{
+			name:        "malformed references",
+			backendRefs: []interface{}{"web", map[string]interface{}{"port": int64(80)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "route", "namespace": "apps"},
+				"spec": map[string]interface{}{
+					"rules": []interface{}{map[string]interface{}{"backendRefs": tt.backendRefs}},
+				},
+			}}
+
+			if got := routeBackendServices(route); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldSkipService(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name   string
+		mutate func(svc *corev1.Service)
+		want   bool
+	}{
+		{name: "selector", mutate: func(svc *corev1.Service) {}},
+		{name: "API server", mutate: func(svc *corev1.Service) { svc.Namespace, svc.Name = "default", "kubernetes" }, want: true},
+		{name: "kubernetes in another namespace", mutate: func(svc *corev1.Service) { svc.Name = "kubernetes" }},
+		{name: "ExternalName", mutate: func(svc *corev1.Service) { svc.Spec.Type = corev1.ServiceTypeExternalName }, want: true},
+		{name: "without a selector", mutate: func(svc *corev1.Service) { svc.Spec.Selector = nil }, want: true},
+		{name: "system component", mutate: func(svc *corev1.Service) { svc.Labels = map[string]string{"k8s-app": "kube-dns"} }, want: true},
+		{name: "owned", mutate: func(svc *corev1.Service) {
+			svc.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}}
+		}, want: true},
+		{name: "ignored namespace", mutate: func(svc *corev1.Service) { svc.Namespace = "kube-system" }, want: true},
+		{name: "protected label", mutate: func(svc *corev1.Service) { svc.Labels = map[string]string{"janitor.k8s.io/keep": "true"} }, want: true},
+		{name: "terminating", mutate: func(svc *corev1.Service) { svc.DeletionTimestamp = &now }, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.IgnoreNamespaces = []string{"kube-system"}
+			policy.Spec.ProtectedLabels = []string{"janitor.k8s.io/keep"}
+
+			svc := newTestService("web", nil)
+			tt.mutate(svc)
+			if got := NewServicesCleaner().shouldSkipService(svc, newTestContext(policy)); got != tt.want {
+				t.Errorf("got skip %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
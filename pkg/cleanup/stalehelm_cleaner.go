@@ -0,0 +1,225 @@
+package cleanup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	helmStatusDeployed        = "deployed"
+	helmStatusSuperseded      = "superseded"
+	helmStatusFailed          = "failed"
+	helmStatusPendingInstall  = "pending-install"
+	helmStatusPendingUpgrade  = "pending-upgrade"
+	helmStatusPendingRollback = "pending-rollback"
+)
+
+// gzipMagic is the header Helm uses to detect compressed release payloads
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// helmRelease holds the fields of a Helm release record needed for cleanup
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status       string `json:"status"`
+		LastDeployed string `json:"last_deployed"`
+	} `json:"info"`
+}
+
+// helmRevision is a decoded release record together with its storage Secret
+type helmRevision struct {
+	Secret  *corev1.Secret
+	Release helmRelease
+	Time    time.Time
+}
+
+// StaleHelmReleasesCleaner handles cleanup of failed/orphaned Helm releases
+type StaleHelmReleasesCleaner struct{}
+
+// NewStaleHelmReleasesCleaner creates a new stale Helm releases cleaner
+func NewStaleHelmReleasesCleaner() *StaleHelmReleasesCleaner {
+	return &StaleHelmReleasesCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *StaleHelmReleasesCleaner) Name() string {
+	return "stalehelm"
+}
+
+// Enabled reports whether the policy enables stale Helm releases cleanup
+func (c *StaleHelmReleasesCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.StaleHelmReleases != nil && policy.Spec.Cleanup.StaleHelmReleases.Enabled
+}
+
+// Plan finds stale Helm release revisions to delete
+func (c *StaleHelmReleasesCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("stalehelm-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.StaleHelmReleases
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	// Parse duration
+	olderThan, err := time.ParseDuration(config.OlderThan)
+	if err != nil {
+		log.Error(err, "Failed to parse olderThan duration", "duration", config.OlderThan)
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	cutoffTime := time.Now().Add(-olderThan)
+
+	// Get all Secrets
+	var secretList corev1.SecretList
+	if err := cleanupCtx.Client.List(ctx, &secretList); err != nil {
+		log.Error(err, "Failed to list Secrets")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	// Decode release records and group them by release
+	releases := make(map[string][]helmRevision)
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if secret.Type != helmReleaseSecretType {
+			continue
+		}
+		stats.Scanned++
+
+		if IsNamespaceIgnored(secret.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) ||
+			IsProtected(secret.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) ||
+			secret.DeletionTimestamp != nil {
+			stats.Skipped++
+			continue
+		}
+
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			log.V(1).Info("Unable to decode Helm release, skipping", "name", secret.Name, "namespace", secret.Namespace, "error", err.Error())
+			stats.Skipped++
+			continue
+		}
+
+		revision := helmRevision{Secret: secret, Release: *release, Time: secret.CreationTimestamp.Time}
+		if deployed, err := time.Parse(time.RFC3339Nano, release.Info.LastDeployed); err == nil {
+			revision.Time = deployed
+		}
+
+		key := secret.Namespace + "/" + release.Name
+		releases[key] = append(releases[key], revision)
+	}
+
+	// Process each release
+	var candidates []Candidate
+	for _, revisions := range releases {
+		// Newest revision first
+		sort.Slice(revisions, func(i, j int) bool {
+			return revisions[i].Release.Version > revisions[j].Release.Version
+		})
+
+		for index, revision := range revisions {
+			reason, rule := c.cleanupReason(index, revision, config, cutoffTime)
+			if reason == "" {
+				stats.Skipped++
+				continue
+			}
+
+			candidates = append(candidates, Candidate{
+				Object:      revision.Secret,
+				Kind:        "Secret",
+				Description: "Helm release revision",
+				Action:      ActionDelete,
+				Reason:      reason,
+				Age:         time.Since(revision.Time),
+				Rule:        rule,
+			})
+		}
+	}
+
+	log.Info("Stale Helm releases planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// cleanupReason explains why a revision should be deleted and which setting matched, or returns
+// empty strings to keep it. index is the position of the revision in its release history, newest first.
+func (c *StaleHelmReleasesCleaner) cleanupReason(index int, revision helmRevision, config *opsv1alpha1.StaleHelmReleasesCleanupConfig, cutoffTime time.Time) (string, string) {
+	status := revision.Release.Info.Status
+
+	// The deployed revision is what Helm considers the current state of the release
+	if status == helmStatusDeployed {
+		return "", ""
+	}
+
+	stale := status == helmStatusFailed
+	if !config.FailedOnly {
+		stale = stale || status == helmStatusPendingInstall || status == helmStatusPendingUpgrade || status == helmStatusPendingRollback
+	}
+	if stale && revision.Time.Before(cutoffTime) {
+		return fmt.Sprintf("revision %d is %s since %s", revision.Release.Version, status, revision.Time.UTC().Format(time.RFC3339)),
+			"olderThan=" + config.OlderThan
+	}
+
+	if config.MaxHistory != nil && index >= int(*config.MaxHistory) && (status == helmStatusSuperseded || status == helmStatusFailed) {
+		return fmt.Sprintf("revision %d is %s and beyond the history depth of %d", revision.Release.Version, status, *config.MaxHistory),
+			fmt.Sprintf("maxHistory=%d", *config.MaxHistory)
+	}
+
+	return "", ""
+}
+
+// decodeHelmRelease decodes a Helm release record: base64, optionally gzip compressed, JSON
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("release data is empty")
+	}
+
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(decoded, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+	decoded = decoded[:n]
+
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer reader.Close()
+
+		decoded, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release: %w", err)
+	}
+	if release.Name == "" {
+		return nil, fmt.Errorf("release has no name")
+	}
+
+	return &release, nil
+}
@@ -0,0 +1,67 @@
+package cleanup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"testing"
+)
+
+// encodeHelmRelease encodes a release record the way Helm stores it in the release Secret
+func encodeHelmRelease(t *testing.T, record string, compress bool) []byte {
+	t.Helper()
+
+	data := []byte(record)
+	if compress {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	}
+	return []byte(base64.StdEncoding.EncodeToString(data))
+}
+
+func TestDecodeHelmRelease(t *testing.T) {
+	const record = `{"name":"web","namespace":"apps","version":3,"info":{"status":"failed","last_deployed":"2024-01-02T03:04:05Z"}}`
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    helmRelease
+		wantErr bool
+	}{
+		{name: "gzip compressed", data: encodeHelmRelease(t, record, true), want: helmRelease{Name: "web", Namespace: "apps", Version: 3}},
+		{name: "uncompressed", data: encodeHelmRelease(t, record, false), want: helmRelease{Name: "web", Namespace: "apps", Version: 3}},
+		{name: "empty", data: nil, wantErr: true},
+		{name: "not base64", data: []byte("%%%"), wantErr: true},
+		{name: "truncated gzip stream", data: []byte(base64.StdEncoding.EncodeToString(append(append([]byte{}, gzipMagic...), 0, 0))), wantErr: true},
+		{name: "not JSON", data: encodeHelmRelease(t, "release", true), wantErr: true},
+		{name: "missing name", data: encodeHelmRelease(t, `{"version":1}`, true), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, err := decodeHelmRelease(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got release %+v", release)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if release.Name != tt.want.Name || release.Namespace != tt.want.Namespace || release.Version != tt.want.Version {
+				t.Errorf("got release %+v, want %+v", *release, tt.want)
+			}
+			if release.Info.Status != "failed" || release.Info.LastDeployed != "2024-01-02T03:04:05Z" {
+				t.Errorf("got info %+v", release.Info)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// TerminatingStrategyAuto picks the action based on why the pod is stuck
+	TerminatingStrategyAuto = "auto"
+
+	// TerminatingStrategyForceDelete deletes stuck pods with a zero grace period
+	TerminatingStrategyForceDelete = "forceDelete"
+
+	// TerminatingStrategyRemoveFinalizers strips finalizers from stuck pods
+	TerminatingStrategyRemoveFinalizers = "removeFinalizers"
+)
+
+// TerminatingPodsCleaner handles cleanup of stuck terminating Pods
+type TerminatingPodsCleaner struct{}
+
+// NewTerminatingPodsCleaner creates a new terminating Pods cleaner
+func NewTerminatingPodsCleaner() *TerminatingPodsCleaner {
+	return &TerminatingPodsCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *TerminatingPodsCleaner) Name() string {
+	return "terminatingpods"
+}
+
+// Enabled reports whether the policy enables terminating pods cleanup
+func (c *TerminatingPodsCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.TerminatingPods != nil && policy.Spec.Cleanup.TerminatingPods.Enabled
+}
+
+// Plan finds stuck terminating Pods to unblock
+func (c *TerminatingPodsCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("terminatingpods-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.TerminatingPods
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	// Parse duration
+	stuckFor, err := time.ParseDuration(config.StuckFor)
+	if err != nil {
+		log.Error(err, "Failed to parse stuckFor duration", "duration", config.StuckFor)
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	strategy := config.Strategy
+	if strategy == "" {
+		strategy = TerminatingStrategyAuto
+	}
+
+	// Get all pods
+	var podList corev1.PodList
+	if err := cleanupCtx.Client.List(ctx, &podList); err != nil {
+		log.Error(err, "Failed to list pods")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	// Get all nodes to check node health
+	var nodeList corev1.NodeList
+	if err := cleanupCtx.Client.List(ctx, &nodeList); err != nil {
+		log.Error(err, "Failed to list nodes")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	nodeReady := make(map[string]bool)
+	for _, node := range nodeList.Items {
+		nodeReady[node.Name] = isNodeReady(&node)
+	}
+
+	now := time.Now()
+
+	// Process each terminating pod
+	var candidates []Candidate
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp == nil {
+			continue
+		}
+		stats.Scanned++
+
+		if c.shouldSkipPod(pod, cleanupCtx) {
+			log.V(1).Info("Skipping terminating pod", "name", pod.Name, "namespace", pod.Namespace)
+			stats.Skipped++
+			continue
+		}
+
+		// The deletion timestamp is set to the end of the grace period, so it already includes it
+		stuckSince := pod.DeletionTimestamp.Time
+		if now.Sub(stuckSince) < stuckFor {
+			log.V(1).Info("Pod has not been terminating long enough, skipping", "name", pod.Name, "namespace", pod.Namespace, "stuckSince", stuckSince)
+			stats.Skipped++
+			continue
+		}
+
+		nodeLost := false
+		nodeState := "Ready"
+		if ready, exists := nodeReady[pod.Spec.NodeName]; pod.Spec.NodeName != "" && !exists {
+			nodeLost = true
+			nodeState = "Missing"
+		} else if pod.Spec.NodeName != "" && !ready {
+			nodeLost = true
+			nodeState = "NotReady"
+		}
+		hasFinalizers := len(pod.Finalizers) > 0
+
+		forceDelete, removeFinalizers := c.planActions(strategy, nodeLost, hasFinalizers)
+
+		// Force-deleting a StatefulSet pod whose node is still running it breaks at-most-one semantics
+		if forceDelete && !nodeLost && isStatefulSetPod(pod) {
+			log.Info("Refusing to force-delete StatefulSet pod on a Ready node", "name", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName)
+			forceDelete = false
+		}
+
+		if !forceDelete && !removeFinalizers {
+			log.Info("Pod is stuck terminating but no safe action applies", "name", pod.Name, "namespace", pod.Namespace, "node", pod.Spec.NodeName, "nodeState", nodeState, "finalizers", pod.Finalizers)
+			message := fmt.Sprintf("Pod stuck terminating since %s on node %q (%s) with finalizers %v", stuckSince.UTC().Format(time.RFC3339), pod.Spec.NodeName, nodeState, pod.Finalizers)
+			cleanupCtx.EventRecorder.Event(pod, "Warning", "StuckTerminating", message)
+			cleanupCtx.Report(opsv1alpha1.Finding{
+				Cleaner:   c.Name(),
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Reason:    "StuckTerminating",
+				Message:   message,
+			})
+			stats.Skipped++
+			continue
+		}
+
+		age := now.Sub(stuckSince)
+		rule := "stuckFor=" + config.StuckFor + ",strategy=" + strategy
+
+		// Finalizers are removed first, the pod may then go away without being force deleted
+		if removeFinalizers {
+			candidates = append(candidates, Candidate{
+				Object:      pod,
+				Kind:        "Pod",
+				Description: "stuck terminating pod",
+				Action:      ActionPatch,
+				Reason:      fmt.Sprintf("removing finalizers %v (node %s)", pod.Finalizers, nodeState),
+				Age:         age,
+				Rule:        rule,
+				Mutate: func() {
+					pod.Finalizers = nil
+				},
+			})
+		}
+
+		if forceDelete {
+			candidates = append(candidates, Candidate{
+				Object:        pod,
+				Kind:          "Pod",
+				Description:   "stuck terminating pod",
+				Action:        ActionDelete,
+				Reason:        fmt.Sprintf("force deleting with a zero grace period (node %s)", nodeState),
+				Age:           age,
+				Rule:          rule,
+				DeleteOptions: []client.DeleteOption{client.GracePeriodSeconds(0)},
+			})
+		}
+	}
+
+	log.Info("Terminating pods planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// planActions decides whether to force-delete and/or strip finalizers for a stuck pod
+func (c *TerminatingPodsCleaner) planActions(strategy string, nodeLost, hasFinalizers bool) (forceDelete, removeFinalizers bool) {
+	switch strategy {
+	case TerminatingStrategyForceDelete:
+		return true, false
+	case TerminatingStrategyRemoveFinalizers:
+		return false, hasFinalizers
+	default:
+		// A pod on a healthy node without finalizers is stuck in the kubelet; leave it for a human
+		return nodeLost, hasFinalizers
+	}
+}
+
+// shouldSkipPod determines if a terminating pod should be skipped
+func (c *TerminatingPodsCleaner) shouldSkipPod(pod *corev1.Pod, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if IsNamespaceIgnored(pod.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if pod has protected labels
+	if IsProtected(pod.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	return false
+}
+
+// isNodeReady checks if the node reports a Ready condition of True
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isStatefulSetPod checks if a pod is controlled by a StatefulSet
+func isStatefulSetPod(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "StatefulSet" && ref.Controller != nil && *ref.Controller {
+			return true
+		}
+	}
+	return false
+}
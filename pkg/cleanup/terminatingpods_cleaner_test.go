@@ -0,0 +1,89 @@
+package cleanup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+func TestTerminatingPodsPlanActions(t *testing.T) {
+	tests := []struct {
+		strategy             string
+		nodeLost             bool
+		hasFinalizers        bool
+		wantForceDelete      bool
+		wantRemoveFinalizers bool
+	}{
+		{strategy: TerminatingStrategyAuto, nodeLost: false, hasFinalizers: false},
+		{strategy: TerminatingStrategyAuto, nodeLost: true, hasFinalizers: false, wantForceDelete: true},
+		{strategy: TerminatingStrategyAuto, nodeLost: false, hasFinalizers: true, wantRemoveFinalizers: true},
+		{strategy: TerminatingStrategyAuto, nodeLost: true, hasFinalizers: true, wantForceDelete: true, wantRemoveFinalizers: true},
+		{strategy: TerminatingStrategyForceDelete, nodeLost: false, hasFinalizers: false, wantForceDelete: true},
+		{strategy: TerminatingStrategyForceDelete, nodeLost: true, hasFinalizers: true, wantForceDelete: true},
+		{strategy: TerminatingStrategyRemoveFinalizers, nodeLost: true, hasFinalizers: false},
+		{strategy: TerminatingStrategyRemoveFinalizers, nodeLost: false, hasFinalizers: true, wantRemoveFinalizers: true},
+	}
+
+	for _, tt := range tests {
+		forceDelete, removeFinalizers := NewTerminatingPodsCleaner().planActions(tt.strategy, tt.nodeLost, tt.hasFinalizers)
+		if forceDelete != tt.wantForceDelete || removeFinalizers != tt.wantRemoveFinalizers {
+			t.Errorf("planActions(%s, nodeLost=%v, hasFinalizers=%v) = (%v, %v), want (%v, %v)",
+				tt.strategy, tt.nodeLost, tt.hasFinalizers, forceDelete, removeFinalizers, tt.wantForceDelete, tt.wantRemoveFinalizers)
+		}
+	}
+}
+
+func TestTerminatingPodsStuckSince(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		deletedAt   time.Time
+		gracePeriod int64
+		wantStuck   bool
+	}{
+		{name: "within the grace period", deletedAt: now.Add(30 * time.Second), gracePeriod: 30},
+		{name: "terminating for less than stuckFor", deletedAt: now.Add(-10 * time.Minute), gracePeriod: 30},
+		{name: "terminating for longer than stuckFor", deletedAt: now.Add(-20 * time.Minute), gracePeriod: 30, wantStuck: true},
+		{
+			// The deletion timestamp already includes the grace period, it is not added again
+			name:        "long grace period already elapsed",
+			deletedAt:   now.Add(-16 * time.Minute),
+			gracePeriod: 600,
+			wantStuck:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:                       "web",
+				Namespace:                  "apps",
+				Finalizers:                 []string{"example.com/block"},
+				DeletionTimestamp:          &metav1.Time{Time: tt.deletedAt},
+				DeletionGracePeriodSeconds: ptr.To(tt.gracePeriod),
+			}}
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Cleanup.TerminatingPods = &opsv1alpha1.TerminatingPodsCleanupConfig{Enabled: true, StuckFor: "15m"}
+
+			candidates, _, err := NewTerminatingPodsCleaner().Plan(context.Background(), newTestContext(policy, pod))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stuck := len(candidates) > 0; stuck != tt.wantStuck {
+				t.Fatalf("got stuck %v, want %v", stuck, tt.wantStuck)
+			}
+			for _, candidate := range candidates {
+				if age := now.Sub(tt.deletedAt); candidate.Age < age {
+					t.Errorf("got age %s, want at least %s", candidate.Age, age)
+				}
+			}
+		})
+	}
+}
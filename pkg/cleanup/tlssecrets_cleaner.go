@@ -0,0 +1,271 @@
+package cleanup
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// certManagerCertificateAnnotation is set by cert-manager on Secrets it issues
+	certManagerCertificateAnnotation = "cert-manager.io/certificate-name"
+)
+
+// TLSSecretsCleaner handles cleanup of expired TLS certificates
+type TLSSecretsCleaner struct{}
+
+// NewTLSSecretsCleaner creates a new TLS Secrets cleaner
+func NewTLSSecretsCleaner() *TLSSecretsCleaner {
+	return &TLSSecretsCleaner{}
+}
+
+// Name returns the name of the cleaner
+func (c *TLSSecretsCleaner) Name() string {
+	return "tlssecrets"
+}
+
+// Enabled reports whether the policy enables TLS Secrets cleanup
+func (c *TLSSecretsCleaner) Enabled(policy *opsv1alpha1.JanitorPolicy) bool {
+	return policy.Spec.Cleanup.TLSSecrets != nil && policy.Spec.Cleanup.TLSSecrets.Enabled
+}
+
+// Plan finds expired TLS Secrets to delete
+func (c *TLSSecretsCleaner) Plan(ctx context.Context, cleanupCtx *Context) ([]Candidate, *opsv1alpha1.ResourceTypeStats, error) {
+	log := cleanupCtx.Logger.WithName("tlssecrets-cleaner")
+	stats := &opsv1alpha1.ResourceTypeStats{}
+
+	config := cleanupCtx.Policy.Spec.Cleanup.TLSSecrets
+	if config == nil || !config.Enabled {
+		return nil, stats, nil
+	}
+
+	// Parse duration
+	var expiringWithin time.Duration
+	if config.ExpiringWithin != "" {
+		parsed, err := time.ParseDuration(config.ExpiringWithin)
+		if err != nil {
+			log.Error(err, "Failed to parse expiringWithin duration", "duration", config.ExpiringWithin)
+			stats.Errors++
+			return nil, stats, err
+		}
+		expiringWithin = parsed
+	}
+
+	// Get all Secrets
+	var secretList corev1.SecretList
+	if err := cleanupCtx.Client.List(ctx, &secretList); err != nil {
+		log.Error(err, "Failed to list Secrets")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	// Build map of Secrets referenced by Ingress TLS entries
+	var ingressList networkingv1.IngressList
+	if err := cleanupCtx.Client.List(ctx, &ingressList); err != nil {
+		log.Error(err, "Failed to list Ingresses")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	ingressRefs := make(map[string]bool)
+	for _, ingress := range ingressList.Items {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				ingressRefs[ingress.Namespace+"/"+tls.SecretName] = true
+			}
+		}
+	}
+
+	gatewayRefs, err := gatewayCertificateReferences(ctx, cleanupCtx.Client)
+	if err != nil {
+		log.Error(err, "Failed to list Gateways")
+		stats.Errors++
+		return nil, stats, err
+	}
+
+	// Build map of Secrets mounted or referenced by workloads, including scaled-down ones
+	specs, err := listPodSpecs(ctx, cleanupCtx.Client)
+	if err != nil {
+		log.Error(err, "Failed to list workloads")
+		stats.Errors++
+		return nil, stats, err
+	}
+	workloadRefs := secretReferences(specs)
+
+	now := time.Now()
+
+	// Process each TLS Secret
+	var candidates []Candidate
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if secret.Type != corev1.SecretTypeTLS {
+			continue
+		}
+		stats.Scanned++
+
+		if c.shouldSkipSecret(secret, cleanupCtx) {
+			log.V(1).Info("Skipping TLS Secret", "name", secret.Name, "namespace", secret.Namespace)
+			stats.Skipped++
+			continue
+		}
+
+		leaf, err := parseLeafCertificate(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			log.V(1).Info("Unable to parse certificate, skipping", "name", secret.Name, "namespace", secret.Namespace, "error", err.Error())
+			c.report(cleanupCtx, secret, "InvalidCertificate", fmt.Sprintf("Unable to parse %s: %v", corev1.TLSCertKey, err))
+			stats.Skipped++
+			continue
+		}
+
+		notAfter := leaf.NotAfter
+		expired := now.After(notAfter)
+		expiringSoon := !expired && expiringWithin > 0 && notAfter.Before(now.Add(expiringWithin))
+
+		if !expired && !expiringSoon {
+			log.V(1).Info("Certificate is still valid, skipping", "name", secret.Name, "namespace", secret.Namespace, "notAfter", notAfter)
+			stats.Skipped++
+			continue
+		}
+
+		// Certificates that are still valid are only reported, never deleted
+		if expiringSoon {
+			log.Info("Certificate is expiring soon", "name", secret.Name, "namespace", secret.Namespace, "notAfter", notAfter)
+			c.report(cleanupCtx, secret, "CertificateExpiring", fmt.Sprintf("Certificate %q expires at %s", leaf.Subject.CommonName, notAfter.UTC().Format(time.RFC3339)))
+			stats.Skipped++
+			continue
+		}
+
+		reason := "CertificateExpired"
+		message := fmt.Sprintf("Certificate %q expired at %s", leaf.Subject.CommonName, notAfter.UTC().Format(time.RFC3339))
+
+		// Secrets that will be renewed in place are reported, not deleted
+		if owner := c.renewalOwner(secret, ingressRefs, gatewayRefs, workloadRefs); owner != "" {
+			log.Info("Certificate is still in use, reporting only", "name", secret.Name, "namespace", secret.Namespace, "notAfter", notAfter, "usedBy", owner)
+			c.report(cleanupCtx, secret, reason, fmt.Sprintf("%s; not deleted because it is %s", message, owner))
+			stats.Skipped++
+			continue
+		}
+
+		candidates = append(candidates, Candidate{
+			Object:      secret,
+			Kind:        "Secret",
+			Description: "TLS Secret",
+			Action:      ActionDelete,
+			Reason:      message,
+			Age:         now.Sub(notAfter),
+			Rule:        "expired",
+		})
+	}
+
+	log.Info("TLS Secrets planning completed",
+		"scanned", stats.Scanned,
+		"candidates", len(candidates),
+		"skipped", stats.Skipped,
+		"errors", stats.Errors)
+
+	return candidates, stats, nil
+}
+
+// shouldSkipSecret determines if a TLS Secret should be skipped
+func (c *TLSSecretsCleaner) shouldSkipSecret(secret *corev1.Secret, cleanupCtx *Context) bool {
+	// Check if namespace is ignored
+	if IsNamespaceIgnored(secret.Namespace, cleanupCtx.Policy.Spec.IgnoreNamespaces) {
+		return true
+	}
+
+	// Check if Secret has protected labels
+	if IsProtected(secret.Labels, cleanupCtx.Policy.Spec.ProtectedLabels) {
+		return true
+	}
+
+	// Skip if Secret is in terminating state
+	if secret.DeletionTimestamp != nil {
+		return true
+	}
+
+	return false
+}
+
+// renewalOwner describes what still uses the Secret, or returns an empty string if nothing does
+func (c *TLSSecretsCleaner) renewalOwner(secret *corev1.Secret, ingressRefs, gatewayRefs, workloadRefs map[string]bool) string {
+	if name, exists := secret.Annotations[certManagerCertificateAnnotation]; exists {
+		return fmt.Sprintf("managed by cert-manager Certificate %s", name)
+	}
+	for _, ref := range secret.OwnerReferences {
+		if ref.Kind == "Certificate" {
+			return fmt.Sprintf("owned by Certificate %s", ref.Name)
+		}
+	}
+	if ingressRefs[secret.Namespace+"/"+secret.Name] {
+		return "referenced by an Ingress"
+	}
+	if gatewayRefs[secret.Namespace+"/"+secret.Name] {
+		return "referenced by a Gateway"
+	}
+	if workloadRefs[secret.Namespace+"/"+secret.Name] {
+		return "used by a workload"
+	}
+	return ""
+}
+
+// report emits a Warning event and records a finding for a TLS Secret
+func (c *TLSSecretsCleaner) report(cleanupCtx *Context, secret *corev1.Secret, reason, message string) {
+	cleanupCtx.EventRecorder.Event(secret, "Warning", reason, message)
+	cleanupCtx.Report(opsv1alpha1.Finding{
+		Cleaner:   c.Name(),
+		Kind:      "Secret",
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+		Reason:    reason,
+		Message:   message,
+	})
+}
+
+// parseLeafCertificate decodes a PEM bundle and returns the leaf certificate.
+// The leaf is the first certificate that does not issue any other certificate in the bundle,
+// which handles chains stored in either order.
+func parseLeafCertificate(data []byte) (*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+
+	for _, candidate := range certs {
+		issuesOther := false
+		for _, other := range certs {
+			if other != candidate && other.CheckSignatureFrom(candidate) == nil {
+				issuesOther = true
+				break
+			}
+		}
+		if !issuesOther {
+			return candidate, nil
+		}
+	}
+
+	return certs[0], nil
+}
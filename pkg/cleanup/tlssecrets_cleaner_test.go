@@ -0,0 +1,217 @@
+package cleanup
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+// testCertificate is a generated certificate with its PEM encoding and key
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCertificate creates a certificate signed by the parent, or a self-signed one without parent
+func newTestCertificate(t *testing.T, commonName string, isCA bool, notAfter time.Time, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCertificate{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func TestParseLeafCertificate(t *testing.T) {
+	notAfter := time.Now().Add(365 * 24 * time.Hour)
+	root := newTestCertificate(t, "root", true, notAfter, nil)
+	intermediate := newTestCertificate(t, "intermediate", true, notAfter, root)
+	leaf := newTestCertificate(t, "leaf", false, notAfter, intermediate)
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("key")})
+
+	join := func(parts ...[]byte) []byte {
+		var data []byte
+		for _, part := range parts {
+			data = append(data, part...)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "single certificate", data: leaf.pem, want: "leaf"},
+		{name: "chain stored leaf first", data: join(leaf.pem, intermediate.pem, root.pem), want: "leaf"},
+		{name: "chain stored root first", data: join(root.pem, intermediate.pem, leaf.pem), want: "leaf"},
+		{name: "key before certificate", data: join(key, leaf.pem), want: "leaf"},
+		{name: "empty", data: nil, wantErr: true},
+		{name: "not PEM", data: []byte("not a certificate"), wantErr: true},
+		{name: "only a key", data: key, wantErr: true},
+		{name: "corrupt certificate", data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := parseLeafCertificate(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got certificate %q", cert.Subject.CommonName)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cert.Subject.CommonName != tt.want {
+				t.Errorf("got leaf %q, want %q", cert.Subject.CommonName, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLSSecretsCleanerPlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		expiresIn     time.Duration
+		expiredOnly   bool
+		annotations   map[string]string
+		objects       []client.Object
+		wantPlanned   bool
+		wantFindingOf string
+	}{
+		{name: "expired", expiresIn: -time.Hour, expiredOnly: true, wantPlanned: true},
+		{name: "expired without expiredOnly", expiresIn: -time.Hour, wantPlanned: true},
+		{name: "expiring soon", expiresIn: 24 * time.Hour, expiredOnly: true, wantFindingOf: "CertificateExpiring"},
+		{name: "expiring soon without expiredOnly", expiresIn: 24 * time.Hour, wantFindingOf: "CertificateExpiring"},
+		{name: "valid", expiresIn: 365 * 24 * time.Hour},
+		{
+			name:          "expired but renewed by cert-manager",
+			expiresIn:     -time.Hour,
+			annotations:   map[string]string{certManagerCertificateAnnotation: "cert"},
+			wantFindingOf: "CertificateExpired",
+		},
+		{
+			name:      "expired but mounted by a Pod",
+			expiresIn: -time.Hour,
+			objects: []client.Object{&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Volumes:    []corev1.Volume{{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "cert"}}}},
+					Containers: []corev1.Container{{Name: "web"}},
+				},
+			}},
+			wantFindingOf: "CertificateExpired",
+		},
+		{
+			name:      "expired but referenced by a scaled-down Deployment",
+			expiresIn: -time.Hour,
+			objects: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](0),
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+						Name: "api",
+						Env: []corev1.EnvVar{{Name: "TLS_CERT", ValueFrom: &corev1.EnvVarSource{
+							SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "cert"}, Key: corev1.TLSCertKey},
+						}}},
+					}}}},
+				},
+			}},
+			wantFindingOf: "CertificateExpired",
+		},
+		{
+			name:      "expired and mounted in another namespace only",
+			expiresIn: -time.Hour,
+			objects: []client.Object{&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "other"},
+				Spec: corev1.PodSpec{
+					Volumes:    []corev1.Volume{{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "cert"}}}},
+					Containers: []corev1.Container{{Name: "web"}},
+				},
+			}},
+			wantPlanned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cert", Namespace: "default", Annotations: tt.annotations},
+				Type:       corev1.SecretTypeTLS,
+				Data: map[string][]byte{
+					corev1.TLSCertKey: newTestCertificate(t, "example.com", false, time.Now().Add(tt.expiresIn), nil).pem,
+				},
+			}
+			policy := &opsv1alpha1.JanitorPolicy{}
+			policy.Spec.Cleanup.TLSSecrets = &opsv1alpha1.TLSSecretsCleanupConfig{Enabled: true, ExpiredOnly: tt.expiredOnly, ExpiringWithin: "720h"}
+			cleanupCtx := newTestContext(policy, append(tt.objects, secret)...)
+
+			candidates, stats, err := NewTLSSecretsCleaner().Plan(context.Background(), cleanupCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.Scanned != 1 {
+				t.Errorf("scanned %d secrets, want 1", stats.Scanned)
+			}
+			if planned := len(candidates) == 1 && candidates[0].Action == ActionDelete; planned != tt.wantPlanned || len(candidates) > 1 {
+				t.Errorf("got %d candidates, want deletion planned %v", len(candidates), tt.wantPlanned)
+			}
+
+			findings := cleanupCtx.Findings()
+			switch {
+			case tt.wantFindingOf == "" && len(findings) > 0:
+				t.Errorf("unexpected findings %+v", findings)
+			case tt.wantFindingOf != "" && (len(findings) != 1 || findings[0].Reason != tt.wantFindingOf):
+				t.Errorf("got findings %+v, want one with reason %s", findings, tt.wantFindingOf)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ type Server struct {
 	resourcesCleaned *prometheus.CounterVec
 	errorsTotal      *prometheus.CounterVec
 	cleanupDuration  *prometheus.HistogramVec
+	skippedRuns      *prometheus.CounterVec
 }
 
 // NewServer creates a new metrics server
@@ -47,6 +48,13 @@ func NewServer() *Server {
 			},
 			[]string{"resource_type", "namespace", "policy"},
 		),
+		skippedRuns: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kubejanitor_skipped_runs_total",
+				Help: "Total number of cleanup runs skipped",
+			},
+			[]string{"namespace", "policy", "reason"},
+		),
 	}
 }
 
@@ -71,3 +79,8 @@ func (s *Server) RecordCleanupMetrics(stats *opsv1alpha1.CleanupStats, err error
 		}
 	}
 }
+
+// RecordSkippedRun records a cleanup run that was skipped
+func (s *Server) RecordSkippedRun(namespace, policy, reason string) {
+	s.skippedRuns.WithLabelValues(namespace, policy, reason).Inc()
+}
@@ -0,0 +1,214 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	opsv1alpha1 "github.com/automationpi/kubejanitor/api/v1alpha1"
+)
+
+const (
+	// SeverityInfo is used for informational notifications
+	SeverityInfo = "info"
+
+	// SeverityWarning is used for notifications that need attention
+	SeverityWarning = "warning"
+
+	// emailTimeout bounds the whole exchange with the SMTP server
+	emailTimeout = 30 * time.Second
+)
+
+// Message is a notification sent to the channels configured in a policy
+type Message struct {
+	Policy    string `json:"policy"`
+	Namespace string `json:"namespace"`
+	Severity  string `json:"severity"`
+	Title     string `json:"title"`
+	Text      string `json:"text"`
+}
+
+// Notifier delivers messages to Slack, email and webhook channels
+type Notifier struct {
+	httpClient *http.Client
+}
+
+// NewNotifier creates a new notifier
+func NewNotifier() *Notifier {
+	return &Notifier{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send delivers the message to every enabled channel in the configuration
+func (n *Notifier) Send(ctx context.Context, config *opsv1alpha1.NotificationConfig, msg Message) error {
+	if config == nil {
+		return nil
+	}
+
+	var errs []error
+
+	if config.Slack != nil && config.Slack.Enabled {
+		if err := n.sendSlack(ctx, config.Slack, msg); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %w", err))
+		}
+	}
+
+	if config.Webhook != nil && config.Webhook.Enabled {
+		if err := n.sendWebhook(ctx, config.Webhook, msg); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+
+	if config.Email != nil && config.Email.Enabled {
+		if err := n.sendEmail(ctx, config.Email, msg); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendSlack posts the message to a Slack incoming webhook
+func (n *Notifier) sendSlack(ctx context.Context, config *opsv1alpha1.SlackConfig, msg Message) error {
+	payload := map[string]string{
+		"text": fmt.Sprintf("*[%s] %s* (policy %s/%s)\n%s", strings.ToUpper(msg.Severity), msg.Title, msg.Namespace, msg.Policy, msg.Text),
+	}
+	if config.Channel != "" {
+		payload["channel"] = config.Channel
+	}
+	return n.postJSON(ctx, config.WebhookURL, nil, payload)
+}
+
+// sendWebhook posts the message as JSON to a generic webhook
+func (n *Notifier) sendWebhook(ctx context.Context, config *opsv1alpha1.WebhookConfig, msg Message) error {
+	return n.postJSON(ctx, config.URL, config.Headers, msg)
+}
+
+// sendEmail sends the message through the configured SMTP server. The exchange is aborted when
+// the context is cancelled or emailTimeout has passed, so an unresponsive server cannot block a run.
+func (n *Notifier) sendEmail(ctx context.Context, config *opsv1alpha1.EmailConfig, msg Message) error {
+	if len(config.To) == 0 {
+		return errors.New("no recipients configured")
+	}
+
+	addr := net.JoinHostPort(config.SMTPServer, strconv.Itoa(int(config.SMTPPort)))
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.SMTPServer)
+	}
+
+	from := config.Username
+	if from == "" {
+		from = "kubejanitor@" + config.SMTPServer
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", from)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&body, "Subject: [kubejanitor] %s\r\n", msg.Title)
+	fmt.Fprintf(&body, "\r\n")
+	fmt.Fprintf(&body, "Policy: %s/%s\r\nSeverity: %s\r\n\r\n%s\r\n", msg.Namespace, msg.Policy, msg.Severity, msg.Text)
+
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+
+	dialer := net.Dialer{Timeout: emailTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	// Cancelling the context interrupts a blocked read or write
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, config.SMTPServer)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: config.SMTPServer, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server does not support authentication")
+		}
+		if err := c.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, to := range config.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// postJSON sends a JSON payload and treats any non-2xx response as an error
+func (n *Notifier) postJSON(ctx context.Context, url string, headers map[string]string, payload interface{}) error {
+	if url == "" {
+		return errors.New("no URL configured")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}